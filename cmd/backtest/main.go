@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// backtest runs handlers on recorded values (csv or json lines) and prints the detected anomalies with summary statistics,
+// to tune handler configs before they are deployed. no valkey, mongodb, kafka or notifier is needed.
+//
+//	go run ./cmd/backtest -input values.csv -handlers big_jump_anom_volume_water_liter -handler-config handlers.json
+//
+// csv files need a header with the columns device_id, timestamp and value (service_id is optional);
+// other files are read as json lines like {"device_id":"d1","service_id":"s1","timestamp":"2025-01-01T00:00:00Z","value":42}
+//
+// with -labels, the detections are scored against labelled anomalies (precision per episode, recall, f1 and detection delay),
+// e.g. to compare variants of a handler type with different parameters in the handler config:
+//
+//	go run ./cmd/backtest -input values.jsonl -labels labels.csv -handler-config big_jump_variants.json
+//
+// label files contain the columns/fields device_id, service_id (optional), start and end
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/backtest"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	input := flag.String("input", "", "csv (.csv) or json lines file with the recorded values")
+	handlers := flag.String("handlers", "", "comma separated names of the handlers to run; all handlers if empty")
+	handlerConfig := flag.String("handler-config", "", "optional handler config file (see handlers.example.json)")
+	format := flag.String("format", "text", "output format: text or json")
+	labelsLocation := flag.String("labels", "", "optional csv (.csv) or json lines file with labelled anomaly intervals")
+	tolerance := flag.Duration("tolerance", 0, "detections up to this duration after the end of a label count for the label")
+	flag.Parse()
+
+	if *format != "text" && *format != "json" {
+		log.Fatal("ERROR: unknown -format ", *format)
+	}
+	if *handlerConfig != "" {
+		err := handler.Registry.LoadConfig(*handlerConfig)
+		if err != nil {
+			log.Fatal("ERROR: unable to load handler config", err)
+		}
+	}
+	register := handler.Registry
+	names := []string{}
+	for _, name := range strings.Split(*handlers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		var err error
+		register, err = register.Select(names...)
+		if err != nil {
+			log.Fatal("ERROR: ", err)
+		}
+	}
+	records, err := backtest.ReadFile(*input)
+	if err != nil {
+		log.Fatal("ERROR: ", err)
+	}
+	var labels []backtest.Label
+	if *labelsLocation != "" {
+		labels, err = backtest.ReadLabelsFile(*labelsLocation)
+		if err != nil {
+			log.Fatal("ERROR: ", err)
+		}
+	}
+
+	entries := register.List()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	reports := []backtest.Report{}
+	evaluations := []backtest.Evaluation{}
+	for _, entry := range entries {
+		report := backtest.Run(entry, records)
+		reports = append(reports, report)
+		if labels != nil {
+			evaluations = append(evaluations, backtest.Evaluate(report, labels, *tolerance))
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		if labels != nil {
+			err = encoder.Encode(map[string]interface{}{"reports": reports, "evaluations": evaluations})
+		} else {
+			err = encoder.Encode(reports)
+		}
+		if err != nil {
+			log.Fatal("ERROR: ", err)
+		}
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "HANDLER\tDEVICE\tSERVICE\tTIME\tSEVERITY\tSCORE\tRESOLVED\tDESCRIPTION")
+	for _, report := range reports {
+		for _, detection := range report.Detections {
+			resolved := "-"
+			if detection.ResolvedAt != 0 {
+				resolved = formatTime(detection.ResolvedAt)
+			}
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%.2f\t%v\t%v\n", detection.Handler, detection.DeviceId, detection.ServiceId, formatTime(detection.Timestamp), detection.Severity, detection.Score, resolved, detection.Description)
+		}
+	}
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "HANDLER\tRECORDS\tSERIES\tEVALUATIONS\tERRORS\tANOMALIES\tANOMALOUS SERIES\tRATE\tINFO/WARNING/CRITICAL\tRESOLVED\tMAX SCORE")
+	for _, report := range reports {
+		s := report.Summary
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%.4f\t%v/%v/%v\t%v\t%.2f\n", s.Handler, s.Records, s.Series, s.Evaluations, s.Errors, s.Anomalies, s.AnomalousSeries, s.AnomalyRate,
+			s.AnomaliesBySeverity[handler.SeverityInfo], s.AnomaliesBySeverity[handler.SeverityWarning], s.AnomaliesBySeverity[handler.SeverityCritical], s.Resolved, s.MaxScore)
+	}
+	if labels != nil {
+		fmt.Fprintln(writer)
+		fmt.Fprintln(writer, "HANDLER\tDETECTIONS\tEPISODES\tTRUE POS\tFALSE POS\tLABELS\tDETECTED\tMISSED\tPRECISION\tRECALL\tF1\tMEAN DELAY\tMAX DELAY")
+		for _, e := range evaluations {
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%.3f\t%.3f\t%.3f\t%v\t%v\n", e.Handler, e.Detections, e.Episodes, e.TruePositives, e.FalsePositives, e.Labels, e.DetectedLabels, e.MissedLabels,
+				e.Precision, e.Recall, e.F1, formatDelay(e.MeanDetectionDelay), formatDelay(e.MaxDetectionDelay))
+		}
+	}
+	err = writer.Flush()
+	if err != nil {
+		log.Fatal("ERROR: ", err)
+	}
+}
+
+func formatTime(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+}
+
+func formatDelay(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+}
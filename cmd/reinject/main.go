@@ -0,0 +1,84 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// reinject passes the events of the dead-letter topic to the handlers, which failed to process them,
+// e.g. after a fix of the marshalling of a service. the events are not published again to their source topics.
+//
+//	go run ./cmd/reinject -config config.json -source-topic urn_infai_ses_service_1 -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/deadletter"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	configLocation := flag.String("config", "config.json", "configuration file")
+	group := flag.String("group", "anomaly-detection-dead-letter-reinject", "consumer group; committed offsets prevent repeated re-injection; runs with filters append a suffix of the filters")
+	sourceTopic := flag.String("source-topic", "", "only re-inject dead letters of this source topic")
+	errorContains := flag.String("error-contains", "", "only re-inject dead letters with errors containing this string")
+	dryRun := flag.Bool("dry-run", false, "only log the dead letters, without reprocessing or commit")
+	idleTimeout := flag.Duration("idle-timeout", 10*time.Second, "stop after this duration without new dead letters")
+	flag.Parse()
+
+	conf, err := configuration.Load(*configLocation)
+	if err != nil {
+		log.Fatal("ERROR: unable to load config", err)
+	}
+	if conf.DeadLetterTopic == "" || conf.DeadLetterTopic == "-" {
+		log.Fatal("ERROR: no dead_letter_topic configured")
+	}
+
+	if conf.HandlerConfigLocation != "" && conf.HandlerConfigLocation != "-" {
+		err = handler.Registry.LoadConfig(conf.HandlerConfigLocation)
+		if err != nil {
+			log.Fatal("ERROR: unable to load handler config", err)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	options := deadletter.ReinjectOptions{
+		KafkaUrl:      conf.KafkaUrl,
+		Topic:         conf.DeadLetterTopic,
+		ConsumerGroup: *group,
+		SourceTopic:   *sourceTopic,
+		ErrorContains: *errorContains,
+		DryRun:        *dryRun,
+		IdleTimeout:   *idleTimeout,
+	}
+	var reinjected, skipped, failed int
+	if *dryRun {
+		//no handler is called in a dry-run; valkey and mongodb are not needed
+		reinjected, skipped, failed, err = deadletter.Reinject(ctx, options, nil)
+	} else {
+		reinjected, skipped, failed, err = controller.Reprocess(ctx, conf, handler.Registry, options)
+	}
+	log.Printf("re-injected %v dead letters, skipped %v, still dead %v\n", reinjected, skipped, failed)
+	if err != nil {
+		log.Fatal("ERROR: ", err)
+	}
+}
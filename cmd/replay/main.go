@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// replay passes historical events of the device topics to selected handlers, to build their state
+// (e.g. the statistics of a newly added handler) before the live consumer reaches them.
+//
+// replayed handlers must have "mode": "disabled" in the handler config of the live service while the replay runs,
+// to not mix historical and live events in their state; enable them (e.g. "mode": "shadow") after the replay.
+// with -suppress-notifications (default), no notifications are sent for the anomalies found by the replay;
+// -mode shadow stores them as shadow anomalies.
+//
+//	go run ./cmd/replay -config config.json -from 2025-01-01T00:00:00Z -handlers big_jump_anom_volume_water_liter -mode shadow
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	configLocation := flag.String("config", "config.json", "configuration file")
+	from := flag.String("from", "", "RFC3339 timestamp of the first replayed event")
+	handlers := flag.String("handlers", "", "comma separated names of the disabled handlers, which receive the events; all disabled handlers if empty")
+	group := flag.String("group", "anomaly-detection-replay", "consumer group of the replay; must differ from the live consumer group")
+	suppressNotifications := flag.Bool("suppress-notifications", true, "do not send notifications for replayed anomalies")
+	mode := flag.String("mode", string(handler.ModeActive), "mode of the replayed handlers: active or shadow")
+	idleTimeout := flag.Duration("idle-timeout", 30*time.Second, "stop after this duration without new events")
+	flag.Parse()
+
+	conf, err := configuration.Load(*configLocation)
+	if err != nil {
+		log.Fatal("ERROR: unable to load config", err)
+	}
+	start, err := time.Parse(time.RFC3339, *from)
+	if err != nil {
+		log.Fatal("ERROR: invalid -from timestamp: ", err)
+	}
+	if conf.HandlerConfigLocation != "" && conf.HandlerConfigLocation != "-" {
+		err = handler.Registry.LoadConfig(conf.HandlerConfigLocation)
+		if err != nil {
+			log.Fatal("ERROR: unable to load handler config", err)
+		}
+	}
+	names := []string{}
+	for _, name := range strings.Split(*handlers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	events, err := controller.Replay(ctx, conf, handler.Registry, controller.ReplayOptions{
+		From:                  start,
+		Handlers:              names,
+		ConsumerGroup:         *group,
+		Mode:                  handler.Mode(*mode),
+		SuppressNotifications: *suppressNotifications,
+		IdleTimeout:           *idleTimeout,
+	})
+	log.Printf("replayed %v events\n", events)
+	if err != nil {
+		log.Fatal("ERROR: ", err)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func init() {
+	endpoints = append(endpoints, &AnomalyEndpoints{})
+}
+
+type AnomalyEndpoints struct{}
+
+// List godoc
+// @Summary      list anomalies
+// @Description  list stored anomalies of devices owned by the requesting user; the total count is returned in the X-Total-Count header
+// @Tags         list, anomalies
+// @Produce      json
+// @Security Bearer
+// @Param        device query string false "filter by device id"
+// @Param        service query string false "filter by service id"
+// @Param        handler query string false "filter by handler name"
+// @Param        status query string false "filter by status (open, acknowledged, resolved)"
+// @Param        severity query string false "filter by severity (info, warning, critical)"
+// @Param        min_score query number false "filter; minimal score (inclusive)"
+// @Param        shadow query boolean false "if true, lists the anomalies of handlers in shadow mode instead of the others; default false"
+// @Param        from query integer false "filter; unix timestamp in seconds (inclusive)"
+// @Param        to query integer false "filter; unix timestamp in seconds (inclusive)"
+// @Param        limit query integer false "default 100"
+// @Param        offset query integer false "default 0"
+// @Param        sort query string false "unix_timestamp.asc or unix_timestamp.desc; default unix_timestamp.desc"
+// @Success      200 {array}  anomalystore.Anomaly
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /anomalies [GET]
+func (this *AnomalyEndpoints) List(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /anomalies", func(writer http.ResponseWriter, request *http.Request) {
+		query, err := parseAnomalyQuery(request.URL.Query())
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, total, err, code := ctrl.ListAnomalies(jwt.GetAuthToken(request), query)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		writer.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+}
+
+// Get godoc
+// @Summary      get anomaly
+// @Description  get anomaly of a device owned by the requesting user
+// @Tags         get, anomalies
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "Anomaly Id"
+// @Success      200 {object}  anomalystore.Anomaly
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /anomalies/{id} [GET]
+func (this *AnomalyEndpoints) Get(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /anomalies/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		result, err, code := ctrl.GetAnomaly(jwt.GetAuthToken(request), request.PathValue("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+}
+
+type StatusUpdate struct {
+	Status anomalystore.Status `json:"status"`
+}
+
+// SetStatus godoc
+// @Summary      set anomaly status
+// @Description  acknowledge or resolve an anomaly of a device owned by the requesting user; open anomalies may be acknowledged or resolved, acknowledged anomalies may be resolved
+// @Tags         update, anomalies
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "Anomaly Id"
+// @Param        message body StatusUpdate true "new status (acknowledged or resolved)"
+// @Success      200 {object}  anomalystore.Anomaly
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /anomalies/{id}/status [PUT]
+func (this *AnomalyEndpoints) SetStatus(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("PUT /anomalies/{id}/status", func(writer http.ResponseWriter, request *http.Request) {
+		update := StatusUpdate{}
+		err := json.NewDecoder(request.Body).Decode(&update)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.SetAnomalyStatus(jwt.GetAuthToken(request), request.PathValue("id"), update.Status)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+}
+
+func parseAnomalyQuery(values url.Values) (query anomalystore.AnomalyQuery, err error) {
+	query = anomalystore.AnomalyQuery{
+		Device:   values.Get("device"),
+		Service:  values.Get("service"),
+		Handler:  values.Get("handler"),
+		Status:   anomalystore.Status(values.Get("status")),
+		Severity: handler.Severity(values.Get("severity")),
+		Sort:     values.Get("sort"),
+	}
+	if query.Status != "" {
+		err = query.Status.Validate()
+		if err != nil {
+			return query, err
+		}
+	}
+	if query.Severity != "" {
+		err = query.Severity.Validate()
+		if err != nil {
+			return query, err
+		}
+	}
+	if value := values.Get("shadow"); value != "" {
+		query.Shadow, err = strconv.ParseBool(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid shadow parameter: %w", err)
+		}
+	}
+	if value := values.Get("min_score"); value != "" {
+		query.MinScore, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid min_score parameter: %w", err)
+		}
+	}
+	intParams := map[string]*int64{
+		"from":   &query.From,
+		"to":     &query.To,
+		"limit":  &query.Limit,
+		"offset": &query.Offset,
+	}
+	for name, target := range intParams {
+		if value := values.Get(name); value != "" {
+			*target, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return query, fmt.Errorf("invalid %v parameter: %w", name, err)
+			}
+		}
+	}
+	if query.Limit < 0 || query.Offset < 0 {
+		return query, errors.New("invalid limit or offset parameter: must not be negative")
+	}
+	return query, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseAnomalyQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    anomalystore.AnomalyQuery
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			query: "",
+			want:  anomalystore.AnomalyQuery{},
+		},
+		{
+			name:  "all",
+			query: "device=d1&service=s1&handler=h1&status=open&severity=critical&min_score=2.5&shadow=true&from=10&to=20&limit=5&offset=15&sort=unix_timestamp.asc",
+			want: anomalystore.AnomalyQuery{
+				Device:   "d1",
+				Service:  "s1",
+				Handler:  "h1",
+				Status:   anomalystore.StatusOpen,
+				Severity: handler.SeverityCritical,
+				Shadow:   true,
+				MinScore: 2.5,
+				From:     10,
+				To:       20,
+				Limit:    5,
+				Offset:   15,
+				Sort:     "unix_timestamp.asc",
+			},
+		},
+		{
+			name:    "invalid limit",
+			query:   "limit=foo",
+			wantErr: true,
+		},
+		{
+			name:    "invalid severity",
+			query:   "severity=urgent",
+			wantErr: true,
+		},
+		{
+			name:    "invalid min_score",
+			query:   "min_score=high",
+			wantErr: true,
+		},
+		{
+			name:    "invalid shadow",
+			query:   "shadow=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "invalid from",
+			query:   "from=2025-01-01",
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			query:   "limit=-1",
+			wantErr: true,
+		},
+		{
+			name:    "negative offset",
+			query:   "offset=-10",
+			wantErr: true,
+		},
+		{
+			name:    "invalid status",
+			query:   "status=closed",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got, err := parseAnomalyQuery(values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseAnomalyQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAnomalyQuery() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
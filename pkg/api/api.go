@@ -0,0 +1,143 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/service-commons/pkg/accesslog"
+	"log"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"sync"
+)
+
+type EndpointMethod = func(config configuration.Config, router *http.ServeMux, ctrl Controller)
+
+var endpoints = []interface{}{} //list of objects with EndpointMethod
+
+func Start(ctx context.Context, wg *sync.WaitGroup, config configuration.Config, ctrl Controller) (err error) {
+	log.Println("start api")
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	startServer(ctx, wg, ":"+config.ApiPort, GetRouter(config, ctrl))
+	if config.MetricsPort != "" && config.MetricsPort != "-" {
+		startServer(ctx, wg, ":"+config.MetricsPort, GetMetricsRouter(ctrl))
+	}
+	return nil
+}
+
+func startServer(ctx context.Context, wg *sync.WaitGroup, addr string, handler http.Handler) {
+	server := &http.Server{Addr: addr, Handler: handler}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Println("listening on ", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			debug.PrintStack()
+			log.Fatal("FATAL:", err)
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		log.Println("api shutdown", server.Addr, server.Shutdown(context.Background()))
+	}()
+}
+
+func GetRouter(config configuration.Config, ctrl Controller) http.Handler {
+	handler := GetRouterWithoutMiddleware(config, ctrl)
+	corsHandler := NewCors(handler)
+	return accesslog.New(corsHandler)
+}
+
+func GetRouterWithoutMiddleware(config configuration.Config, ctrl Controller) http.Handler {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /{$}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+	for _, e := range endpoints {
+		for name, call := range getEndpointMethods(e) {
+			log.Println("add endpoint " + name)
+			call(config, router, ctrl)
+		}
+	}
+	return router
+}
+
+// GetMetricsRouter serves the prometheus metrics on GET /metrics
+// the metrics are served on the internal metrics_port, not on the public api
+func GetMetricsRouter(ctrl Controller) http.Handler {
+	router := http.NewServeMux()
+	router.Handle("GET /metrics", ctrl.MetricsHandler())
+	return router
+}
+
+func getEndpointMethods(e interface{}) map[string]EndpointMethod {
+	result := map[string]EndpointMethod{}
+	objRef := reflect.ValueOf(e)
+	methodCount := objRef.NumMethod()
+	for i := 0; i < methodCount; i++ {
+		m := objRef.Method(i)
+		f, ok := m.Interface().(EndpointMethod)
+		if ok {
+			name := getTypeName(objRef.Type()) + "::" + objRef.Type().Method(i).Name
+			result[name] = f
+		}
+	}
+	return result
+}
+
+func getTypeName(t reflect.Type) (res string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func NewCors(handler http.Handler) *CorsMiddleware {
+	return &CorsMiddleware{handler: handler}
+}
+
+type CorsMiddleware struct {
+	handler http.Handler
+}
+
+func (this *CorsMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		origin = "*"
+	}
+	res.Header().Set("Access-Control-Allow-Origin", origin)
+	res.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, authorization, Authorization, X-Total-Count")
+	res.Header().Set("Access-Control-Expose-Headers", "X-Total-Count")
+	res.Header().Set("Access-Control-Allow-Credentials", "true")
+	res.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+
+	if req.Method == "OPTIONS" {
+		res.WriteHeader(http.StatusOK)
+	} else {
+		this.handler.ServeHTTP(res, req)
+	}
+}
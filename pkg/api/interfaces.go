@@ -0,0 +1,32 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"net/http"
+)
+
+type Controller interface {
+	ListAnomalies(token string, query anomalystore.AnomalyQuery) (result []anomalystore.Anomaly, total int64, err error, code int)
+	GetAnomaly(token string, id string) (result anomalystore.Anomaly, err error, code int)
+	SetAnomalyStatus(token string, id string, status anomalystore.Status) (result anomalystore.Anomaly, err error, code int)
+	Status() model.ServiceStatus
+	Readiness() model.Readiness
+	MetricsHandler() http.Handler
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"net/http"
+	"sync"
+)
+
+var ErrStarting = errors.New("service is starting")
+
+// StartingController allows to start the api before the controller, so that /health is available during the startup.
+// until Set is called, /ready reports the service as not ready and all other requests fail with 503.
+type StartingController struct {
+	mux  sync.RWMutex
+	ctrl Controller
+}
+
+func NewStartingController() *StartingController {
+	return &StartingController{}
+}
+
+// Set passes all following calls to ctrl
+func (this *StartingController) Set(ctrl Controller) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.ctrl = ctrl
+}
+
+func (this *StartingController) get() Controller {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	return this.ctrl
+}
+
+func (this *StartingController) ListAnomalies(token string, query anomalystore.AnomalyQuery) (result []anomalystore.Anomaly, total int64, err error, code int) {
+	ctrl := this.get()
+	if ctrl == nil {
+		return nil, 0, ErrStarting, http.StatusServiceUnavailable
+	}
+	return ctrl.ListAnomalies(token, query)
+}
+
+func (this *StartingController) GetAnomaly(token string, id string) (result anomalystore.Anomaly, err error, code int) {
+	ctrl := this.get()
+	if ctrl == nil {
+		return result, ErrStarting, http.StatusServiceUnavailable
+	}
+	return ctrl.GetAnomaly(token, id)
+}
+
+func (this *StartingController) SetAnomalyStatus(token string, id string, status anomalystore.Status) (result anomalystore.Anomaly, err error, code int) {
+	ctrl := this.get()
+	if ctrl == nil {
+		return result, ErrStarting, http.StatusServiceUnavailable
+	}
+	return ctrl.SetAnomalyStatus(token, id, status)
+}
+
+func (this *StartingController) Status() model.ServiceStatus {
+	ctrl := this.get()
+	if ctrl == nil {
+		return model.ServiceStatus{Failures: map[string]string{}}
+	}
+	return ctrl.Status()
+}
+
+func (this *StartingController) Readiness() model.Readiness {
+	ctrl := this.get()
+	if ctrl == nil {
+		return model.Readiness{Ready: false, Checks: map[string]string{"controller": ErrStarting.Error()}}
+	}
+	return ctrl.Readiness()
+}
+
+// MetricsHandler is requested once while the metrics router is created; the returned handler passes each request to the current controller
+func (this *StartingController) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctrl := this.get()
+		if ctrl == nil {
+			http.Error(writer, ErrStarting.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		ctrl.MetricsHandler().ServeHTTP(writer, request)
+	})
+}
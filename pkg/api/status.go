@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"log"
+	"net/http"
+)
+
+func init() {
+	endpoints = append(endpoints, &StatusEndpoints{})
+}
+
+type StatusEndpoints struct{}
+
+// Status godoc
+// @Summary      service status
+// @Description  reports if the service is degraded, because components (e.g. the event consumer or register reloads) failed and did not recover yet
+// @Tags         status
+// @Produce      json
+// @Success      200 {object}  model.ServiceStatus
+// @Router       /status [GET]
+func (this *StatusEndpoints) Status(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /status", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err := json.NewEncoder(writer).Encode(ctrl.Status())
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+}
+
+// Health godoc
+// @Summary      liveness check
+// @Description  responds with 200 as long as the service is running
+// @Tags         status
+// @Success      200
+// @Router       /health [GET]
+func (this *StatusEndpoints) Health(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /health", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+}
+
+// Ready godoc
+// @Summary      readiness check
+// @Description  checks valkey, mongodb, the event consumer (kafka or mqtt) and if the handler register has been loaded at least once
+// @Tags         status
+// @Produce      json
+// @Success      200 {object}  model.Readiness
+// @Failure      503 {object}  model.Readiness
+// @Router       /ready [GET]
+func (this *StatusEndpoints) Ready(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /ready", func(writer http.ResponseWriter, request *http.Request) {
+		result := ctrl.Readiness()
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if !result.Ready {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+		err := json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+}
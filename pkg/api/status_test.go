@@ -0,0 +1,129 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type readinessController struct {
+	Controller
+	readiness model.Readiness
+}
+
+func (this readinessController) Readiness() model.Readiness {
+	return this.readiness
+}
+
+func (this readinessController) Status() model.ServiceStatus {
+	return model.ServiceStatus{Failures: map[string]string{}}
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		readiness model.Readiness
+		want      int
+	}{
+		{
+			name:      "ready",
+			readiness: model.Readiness{Ready: true, Checks: map[string]string{"valkey": "ok"}},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "not ready",
+			readiness: model.Readiness{Ready: false, Checks: map[string]string{"valkey": "connection refused"}},
+			want:      http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := http.NewServeMux()
+			(&StatusEndpoints{}).Ready(configuration.Config{}, router, readinessController{readiness: tt.readiness})
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			if recorder.Code != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestStartingController(t *testing.T) {
+	starting := NewStartingController()
+	router := GetRouterWithoutMiddleware(configuration.Config{}, starting)
+	request := func(path string) int {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder.Code
+	}
+	tests := []struct {
+		path     string
+		starting int
+		started  int
+	}{
+		{path: "/health", starting: http.StatusOK, started: http.StatusOK},
+		{path: "/ready", starting: http.StatusServiceUnavailable, started: http.StatusOK},
+		{path: "/status", starting: http.StatusOK, started: http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := request(tt.path); code != tt.starting {
+			t.Errorf("%v while starting: expected %v, got %v", tt.path, tt.starting, code)
+		}
+	}
+	starting.Set(readinessController{readiness: model.Readiness{Ready: true, Checks: map[string]string{"valkey": "ok"}}})
+	for _, tt := range tests {
+		if code := request(tt.path); code != tt.started {
+			t.Errorf("%v after start: expected %v, got %v", tt.path, tt.started, code)
+		}
+	}
+}
+
+type metricsController struct {
+	Controller
+}
+
+func (this metricsController) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestMetricsRouter(t *testing.T) {
+	ctrl := metricsController{}
+	tests := []struct {
+		name   string
+		router http.Handler
+		want   int
+	}{
+		{name: "public api", router: GetRouterWithoutMiddleware(configuration.Config{}, ctrl), want: http.StatusNotFound},
+		{name: "metrics", router: GetMetricsRouter(ctrl), want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			if recorder.Code != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, recorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package backtest runs handlers on recorded values, without valkey, mongodb or notifications,
+// to check how a handler (and its parameters) behaves on real data before it is deployed.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"log"
+	"sort"
+)
+
+// Detection is an anomaly found by the handler
+type Detection struct {
+	Handler     string           `json:"handler"`
+	DeviceId    string           `json:"device_id"`
+	ServiceId   string           `json:"service_id,omitempty"`
+	Timestamp   int64            `json:"timestamp"` //unix timestamp in seconds of the value, which triggered the detection
+	Description string           `json:"description"`
+	Score       float64          `json:"score"`
+	Severity    handler.Severity `json:"severity"`
+	ResolvedAt  int64            `json:"resolved_at,omitempty"` //unix timestamp in seconds of the recovery; only set for handlers with auto resolve
+}
+
+type Summary struct {
+	Handler             string                   `json:"handler"`
+	Records             int                      `json:"records"`
+	Series              int                      `json:"series"`      //count of device/service combinations
+	Evaluations         int                      `json:"evaluations"` //handler calls; values are only evaluated if the buffer contains enough values
+	Errors              int                      `json:"errors"`
+	Anomalies           int                      `json:"anomalies"`
+	AnomalousSeries     int                      `json:"anomalous_series"`
+	AnomalyRate         float64                  `json:"anomaly_rate"` //anomalies per evaluation
+	AnomaliesBySeverity map[handler.Severity]int `json:"anomalies_by_severity"`
+	Resolved            int                      `json:"resolved"`
+	MaxScore            float64                  `json:"max_score"`
+}
+
+type Report struct {
+	Detections []Detection `json:"detections"`
+	Summary    Summary     `json:"summary"`
+}
+
+// Run passes the records of each device/service in timestamp order to the handler entry,
+// buffered like in the service (BufferSize or BufferWindow), with a MemoryStore as handler.Store
+// handler errors and panics are counted in the summary and do not stop the run
+func Run(entry handler.Entry, records []Record) Report {
+	report := Report{
+		Detections: []Detection{},
+		Summary: Summary{
+			Handler:             entry.Name,
+			Records:             len(records),
+			AnomaliesBySeverity: map[handler.Severity]int{},
+		},
+	}
+	store := NewMemoryStore()
+	for _, series := range groupSeries(records) {
+		report.Summary.Series++
+		detections, evaluations, errs := runSeries(entry, store, series)
+		report.Summary.Evaluations += evaluations
+		report.Summary.Errors += errs
+		if len(detections) > 0 {
+			report.Summary.AnomalousSeries++
+		}
+		for _, detection := range detections {
+			report.Summary.Anomalies++
+			report.Summary.AnomaliesBySeverity[detection.Severity]++
+			if detection.ResolvedAt != 0 {
+				report.Summary.Resolved++
+			}
+			if detection.Score > report.Summary.MaxScore {
+				report.Summary.MaxScore = detection.Score
+			}
+		}
+		report.Detections = append(report.Detections, detections...)
+	}
+	if report.Summary.Evaluations > 0 {
+		report.Summary.AnomalyRate = float64(report.Summary.Anomalies) / float64(report.Summary.Evaluations)
+	}
+	sort.SliceStable(report.Detections, func(i, j int) bool {
+		return report.Detections[i].Timestamp < report.Detections[j].Timestamp
+	})
+	return report
+}
+
+// groupSeries splits the records by device/service (in order of their first record) and sorts each series by timestamp
+func groupSeries(records []Record) (result [][]Record) {
+	index := map[string]int{}
+	for _, record := range records {
+		key := record.DeviceId + "/" + record.ServiceId
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, nil)
+		}
+		result[i] = append(result[i], record)
+	}
+	for _, series := range result {
+		sort.SliceStable(series, func(i, j int) bool {
+			return series[i].Timestamp < series[j].Timestamp
+		})
+	}
+	return result
+}
+
+func runSeries(entry handler.Entry, store handler.Store, series []Record) (detections []Detection, evaluations int, errs int) {
+	values := []interface{}{}
+	timestamps := []int64{}
+	open := -1 //index of the unresolved detection of auto resolving handlers
+	for _, record := range series {
+		values = append(values, record.Value)
+		timestamps = append(timestamps, record.Timestamp)
+		if entry.BufferWindow > 0 {
+			start := 0
+			for start < len(timestamps) && timestamps[start] < record.Timestamp-int64(entry.BufferWindow.Seconds()) {
+				start++
+			}
+			values, timestamps = values[start:], timestamps[start:]
+		} else if len(values) > entry.BufferSize {
+			values, timestamps = values[len(values)-entry.BufferSize:], timestamps[len(timestamps)-entry.BufferSize:]
+		}
+		if len(values) < entry.BufferSize {
+			continue
+		}
+		evaluations++
+		result, err := evaluate(entry, handler.Context{
+			DeviceId:   record.DeviceId,
+			ServiceId:  record.ServiceId,
+			Timestamp:  record.Timestamp,
+			Timestamps: append([]int64{}, timestamps...),
+			Store:      store,
+			Parameters: entry.Parameters,
+		}, append([]interface{}{}, values...))
+		if err != nil {
+			log.Println("WARNING: handler error", entry.Name, record.DeviceId, record.ServiceId, record.Timestamp, err)
+			errs++
+			continue
+		}
+		if result.Anomaly {
+			detections = append(detections, Detection{
+				Handler:     entry.Name,
+				DeviceId:    record.DeviceId,
+				ServiceId:   record.ServiceId,
+				Timestamp:   record.Timestamp,
+				Description: result.Description,
+				Score:       result.Score,
+				Severity:    result.Severity,
+			})
+			if entry.AutoResolve {
+				open = len(detections) - 1
+			}
+		} else if open >= 0 {
+			detections[open].ResolvedAt = record.Timestamp
+			open = -1
+		}
+	}
+	return detections, evaluations, errs
+}
+
+func evaluate(entry handler.Entry, context handler.Context, values []interface{}) (result handler.Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = handler.Result{}
+			err = errors.New("panic:" + fmt.Sprint(r))
+		}
+	}()
+	return entry.Evaluate(context, values)
+}
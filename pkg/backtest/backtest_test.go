@@ -0,0 +1,169 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backtest
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRead(t *testing.T) {
+	expected := []Record{
+		{DeviceId: "d1", ServiceId: "s1", Timestamp: 60, Value: 1.5},
+		{DeviceId: "d1", ServiceId: "s1", Timestamp: 1735689600, Value: "on"},
+		{DeviceId: "d2", ServiceId: "", Timestamp: 120, Value: true},
+	}
+	tests := []struct {
+		name  string
+		read  func() ([]Record, error)
+		input string
+	}{
+		{
+			name: "csv",
+			read: func() ([]Record, error) {
+				return ReadCSV(strings.NewReader("timestamp,device_id,service_id,value\n60,d1,s1,1.5\n2025-01-01T00:00:00Z,d1,s1,on\n120,d2,,true\n"))
+			},
+		},
+		{
+			name: "json lines",
+			read: func() ([]Record, error) {
+				return ReadJSONLines(strings.NewReader(`{"device_id":"d1","service_id":"s1","timestamp":60,"value":1.5}` + "\n" +
+					`{"device_id":"d1","service_id":"s1","timestamp":"2025-01-01T00:00:00Z","value":"on"}` + "\n\n" +
+					`{"device_id":"d2","timestamp":"120","value":true}`))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.read()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("\n%#v\n%#v", actual, expected)
+			}
+		})
+	}
+
+	t.Run("missing column", func(t *testing.T) {
+		_, err := ReadCSV(strings.NewReader("device_id,value\nd1,1\n"))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("invalid timestamp", func(t *testing.T) {
+		_, err := ReadJSONLines(strings.NewReader(`{"device_id":"d1","timestamp":"yesterday","value":1}`))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+// thresholdHandler finds values above 10 and counts its calls in the store
+type thresholdHandler struct{}
+
+func (this thresholdHandler) Handle(context handler.Context, values []interface{}) (anomaly bool, description string, err error) {
+	if values[len(values)-1] == "panic" {
+		panic("test")
+	}
+	calls := 0.0
+	_ = context.Store.Get(context.PrepareKey("threshold", "calls"), &calls)
+	err = context.Store.Set(context.PrepareKey("threshold", "calls"), calls+1)
+	if err != nil {
+		return false, "", err
+	}
+	newest, ok := values[len(values)-1].(float64)
+	return ok && newest > 10, strings.Repeat("x", len(values)), nil
+}
+
+func TestRun(t *testing.T) {
+	records := []Record{
+		{DeviceId: "d1", Timestamp: 0, Value: 1.0},
+		{DeviceId: "d2", Timestamp: 0, Value: 20.0},
+		{DeviceId: "d1", Timestamp: 120, Value: 20.0},
+		{DeviceId: "d1", Timestamp: 60, Value: 1.0},
+		{DeviceId: "d1", Timestamp: 180, Value: 1.0},
+		{DeviceId: "d2", Timestamp: 60, Value: "panic"},
+	}
+	tests := []struct {
+		name     string
+		options  []handler.Option
+		expected []Detection
+		summary  Summary
+	}{
+		{
+			name: "buffer size",
+			expected: []Detection{
+				{Handler: "threshold", DeviceId: "d1", Timestamp: 120, Description: "xx", Score: 1, Severity: handler.SeverityWarning},
+			},
+			summary: Summary{Handler: "threshold", Records: 6, Series: 2, Evaluations: 4, Errors: 1, Anomalies: 1, AnomalousSeries: 1, AnomalyRate: 0.25,
+				AnomaliesBySeverity: map[handler.Severity]int{handler.SeverityWarning: 1}, MaxScore: 1},
+		},
+		{
+			name:    "buffer window with auto resolve",
+			options: []handler.Option{handler.WithBufferWindow(2 * time.Minute), handler.WithAutoResolve(), handler.WithSeverity(handler.SeverityCritical)},
+			expected: []Detection{
+				{Handler: "threshold", DeviceId: "d1", Timestamp: 120, Description: "xxx", Score: 1, Severity: handler.SeverityCritical, ResolvedAt: 180},
+			},
+			summary: Summary{Handler: "threshold", Records: 6, Series: 2, Evaluations: 4, Errors: 1, Anomalies: 1, AnomalousSeries: 1, AnomalyRate: 0.25,
+				AnomaliesBySeverity: map[handler.Severity]int{handler.SeverityCritical: 1}, Resolved: 1, MaxScore: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			register := handler.NewRegister()
+			err := register.Register("threshold", "f", "a", "c", 2, thresholdHandler{}, test.options...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			report := Run(register.List()[0], records)
+			if !reflect.DeepEqual(report.Detections, test.expected) {
+				t.Errorf("\n%#v\n%#v", report.Detections, test.expected)
+			}
+			if !reflect.DeepEqual(report.Summary, test.summary) {
+				t.Errorf("\n%#v\n%#v", report.Summary, test.summary)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	err := store.Set("key", map[string]interface{}{"count": 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var result map[string]interface{}
+	err = store.Get("key", &result)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"count": 1.0}) {
+		t.Errorf("unexpected value %#v", result)
+	}
+	err = store.Get("unknown", &result)
+	if err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
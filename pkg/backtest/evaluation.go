@@ -0,0 +1,244 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Label is a known anomaly of a device/service between Start and End (unix timestamps in seconds, inclusive)
+// labels without ServiceId apply to all services of the device
+type Label struct {
+	DeviceId  string `json:"device_id"`
+	ServiceId string `json:"service_id,omitempty"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+}
+
+func (this Label) matches(detection Detection, tolerance time.Duration) bool {
+	return detection.DeviceId == this.DeviceId &&
+		(this.ServiceId == "" || detection.ServiceId == this.ServiceId) &&
+		detection.Timestamp >= this.Start &&
+		time.Duration(detection.Timestamp-this.End)*time.Second <= tolerance
+}
+
+// Evaluation counts true and false positives per episode instead of per detection, like the service stores anomalies:
+// an episode is one anomaly of a device/service, which includes all detections until it is resolved (Detection.ResolvedAt).
+// without auto resolve, all detections of a device/service are one episode.
+type Evaluation struct {
+	Handler            string  `json:"handler"`
+	Detections         int     `json:"detections"`
+	Episodes           int     `json:"episodes"`
+	TruePositives      int     `json:"true_positives"`  //episodes with at least one detection within a label
+	FalsePositives     int     `json:"false_positives"` //episodes with all detections outside of all labels
+	Labels             int     `json:"labels"`
+	DetectedLabels     int     `json:"detected_labels"` //labels with at least one detection
+	MissedLabels       int     `json:"missed_labels"`
+	Precision          float64 `json:"precision"` //true positives per episode
+	Recall             float64 `json:"recall"`    //detected labels per label
+	F1                 float64 `json:"f1"`
+	MeanDetectionDelay float64 `json:"mean_detection_delay"` //seconds from the start of detected labels to their first detection
+	MaxDetectionDelay  float64 `json:"max_detection_delay"`
+}
+
+// Evaluate scores the detections of the report against the labelled anomalies (see Evaluation for the counting of episodes)
+// detections up to tolerance after the end of a label still count for the label (e.g. for handlers, which need several values to notice a change)
+// precision, recall and f1 are 0 if they are undefined (no detections or no labels)
+func Evaluate(report Report, labels []Label, tolerance time.Duration) Evaluation {
+	result := Evaluation{
+		Handler:    report.Summary.Handler,
+		Detections: len(report.Detections),
+		Labels:     len(labels),
+	}
+	for _, episode := range episodes(report.Detections) {
+		result.Episodes++
+		truePositive := false
+		for _, detection := range episode {
+			for _, label := range labels {
+				if label.matches(detection, tolerance) {
+					truePositive = true
+				}
+			}
+		}
+		if truePositive {
+			result.TruePositives++
+		} else {
+			result.FalsePositives++
+		}
+	}
+	delays := 0.0
+	for _, label := range labels {
+		first := int64(-1)
+		for _, detection := range report.Detections {
+			if label.matches(detection, tolerance) && (first < 0 || detection.Timestamp < first) {
+				first = detection.Timestamp
+			}
+		}
+		if first < 0 {
+			result.MissedLabels++
+			continue
+		}
+		result.DetectedLabels++
+		delay := float64(first - label.Start)
+		delays += delay
+		if delay > result.MaxDetectionDelay {
+			result.MaxDetectionDelay = delay
+		}
+	}
+	if result.DetectedLabels > 0 {
+		result.MeanDetectionDelay = delays / float64(result.DetectedLabels)
+	}
+	if result.Episodes > 0 {
+		result.Precision = float64(result.TruePositives) / float64(result.Episodes)
+	}
+	if result.Labels > 0 {
+		result.Recall = float64(result.DetectedLabels) / float64(result.Labels)
+	}
+	if result.Precision+result.Recall > 0 {
+		result.F1 = 2 * result.Precision * result.Recall / (result.Precision + result.Recall)
+	}
+	return result
+}
+
+// episodes groups the detections (in timestamp order) by device/service;
+// an episode ends with the detection, which has been resolved
+func episodes(detections []Detection) (result [][]Detection) {
+	open := map[string]int{} //device/service -> index of the unresolved episode in result
+	for _, detection := range detections {
+		key := detection.DeviceId + "/" + detection.ServiceId
+		i, ok := open[key]
+		if !ok {
+			i = len(result)
+			result = append(result, nil)
+			open[key] = i
+		}
+		result[i] = append(result[i], detection)
+		if detection.ResolvedAt != 0 {
+			delete(open, key)
+		}
+	}
+	return result
+}
+
+// ReadLabelsFile reads labels from a csv file (.csv) or a json-lines file (any other extension)
+// csv files need a header with the columns device_id, start and end (service_id is optional)
+// timestamps are unix seconds or RFC3339
+func ReadLabelsFile(location string) ([]Label, error) {
+	file, err := os.Open(location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open labels: %w", err)
+	}
+	defer file.Close()
+	if strings.EqualFold(filepath.Ext(location), ".csv") {
+		return ReadLabelsCSV(file)
+	}
+	return ReadLabelsJSONLines(file)
+}
+
+func ReadLabelsCSV(reader io.Reader) (result []Label, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read csv header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"device_id", "start", "end"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing csv column %v", required)
+		}
+	}
+	serviceColumn, hasService := columns["service_id"]
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read csv: %w", err)
+		}
+		label := Label{DeviceId: row[columns["device_id"]]}
+		if hasService {
+			label.ServiceId = row[serviceColumn]
+		}
+		label.Start, label.End, err = parseInterval(row[columns["start"]], row[columns["end"]])
+		if err != nil {
+			return nil, fmt.Errorf("invalid label in line %v: %w", line, err)
+		}
+		result = append(result, label)
+	}
+}
+
+func ReadLabelsJSONLines(reader io.Reader) (result []Label, err error) {
+	decoder := json.NewDecoder(reader)
+	for i := 1; ; i++ {
+		element := struct {
+			DeviceId  string          `json:"device_id"`
+			ServiceId string          `json:"service_id"`
+			Start     json.RawMessage `json:"start"`
+			End       json.RawMessage `json:"end"`
+		}{}
+		err = decoder.Decode(&element)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal label %v: %w", i, err)
+		}
+		label := Label{DeviceId: element.DeviceId, ServiceId: element.ServiceId}
+		label.Start, label.End, err = parseInterval(rawTimestamp(element.Start), rawTimestamp(element.End))
+		if err != nil {
+			return nil, fmt.Errorf("invalid label %v: %w", i, err)
+		}
+		result = append(result, label)
+	}
+}
+
+func parseInterval(startValue string, endValue string) (start int64, end int64, err error) {
+	start, err = parseTimestamp(startValue)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err = parseTimestamp(endValue)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end: %w", err)
+	}
+	if end < start {
+		return 0, 0, errors.New("end is before start")
+	}
+	return start, end, nil
+}
+
+// rawTimestamp returns json strings unquoted and numbers as they are
+func rawTimestamp(raw json.RawMessage) string {
+	var result string
+	if json.Unmarshal(raw, &result) != nil {
+		result = string(raw)
+	}
+	return result
+}
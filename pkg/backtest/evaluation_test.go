@@ -0,0 +1,150 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backtest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	report := Report{
+		Summary: Summary{Handler: "test"},
+		Detections: []Detection{
+			{DeviceId: "d1", ServiceId: "s1", Timestamp: 100},
+			{DeviceId: "d1", ServiceId: "s1", Timestamp: 160},
+			{DeviceId: "d1", ServiceId: "s2", Timestamp: 500},
+			{DeviceId: "d2", ServiceId: "s1", Timestamp: 100, ResolvedAt: 200},
+			{DeviceId: "d2", ServiceId: "s1", Timestamp: 1030},
+		},
+	}
+	labels := []Label{
+		{DeviceId: "d1", ServiceId: "s1", Start: 40, End: 200},
+		{DeviceId: "d1", Start: 400, End: 600},
+		{DeviceId: "d2", ServiceId: "s1", Start: 900, End: 1000},
+		{DeviceId: "d3", Start: 0, End: 1000},
+	}
+	tests := []struct {
+		name      string
+		tolerance time.Duration
+		expected  Evaluation
+	}{
+		{
+			name: "without tolerance",
+			expected: Evaluation{Handler: "test", Detections: 5, Episodes: 4, TruePositives: 2, FalsePositives: 2, Labels: 4, DetectedLabels: 2, MissedLabels: 2,
+				Precision: 0.5, Recall: 0.5, F1: 0.5, MeanDetectionDelay: 80, MaxDetectionDelay: 100},
+		},
+		{
+			name:      "with tolerance",
+			tolerance: time.Minute,
+			expected: Evaluation{Handler: "test", Detections: 5, Episodes: 4, TruePositives: 3, FalsePositives: 1, Labels: 4, DetectedLabels: 3, MissedLabels: 1,
+				Precision: 0.75, Recall: 0.75, F1: 0.75, MeanDetectionDelay: 290.0 / 3.0, MaxDetectionDelay: 130},
+		},
+		{
+			name:      "without labels",
+			tolerance: time.Minute,
+			expected:  Evaluation{Handler: "test", Detections: 5, Episodes: 4, FalsePositives: 4},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := labels
+			if test.expected.Labels == 0 {
+				l = nil
+			}
+			actual := Evaluate(report, l, test.tolerance)
+			if !reflect.DeepEqual(round(actual), round(test.expected)) {
+				t.Errorf("\n%#v\n%#v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestLabel_matches(t *testing.T) {
+	label := Label{DeviceId: "d1", Start: 100, End: 200}
+	tests := []struct {
+		timestamp int64
+		tolerance time.Duration
+		want      bool
+	}{
+		{timestamp: 99, want: false},
+		{timestamp: 100, want: true},
+		{timestamp: 200, want: true},
+		{timestamp: 201, want: false},
+		{timestamp: 201, tolerance: 999 * time.Millisecond, want: false},
+		{timestamp: 201, tolerance: 1500 * time.Millisecond, want: true},
+		{timestamp: 202, tolerance: 1500 * time.Millisecond, want: false},
+	}
+	for _, tt := range tests {
+		if got := label.matches(Detection{DeviceId: "d1", Timestamp: tt.timestamp}, tt.tolerance); got != tt.want {
+			t.Errorf("matches(%v, %v) = %v, want %v", tt.timestamp, tt.tolerance, got, tt.want)
+		}
+	}
+}
+
+func round(evaluation Evaluation) Evaluation {
+	for _, value := range []*float64{&evaluation.Precision, &evaluation.Recall, &evaluation.F1, &evaluation.MeanDetectionDelay, &evaluation.MaxDetectionDelay} {
+		*value = float64(int64(*value*1e6)) / 1e6
+	}
+	return evaluation
+}
+
+func TestReadLabels(t *testing.T) {
+	expected := []Label{
+		{DeviceId: "d1", ServiceId: "s1", Start: 60, End: 120},
+		{DeviceId: "d2", Start: 1735689600, End: 1735693200},
+	}
+	tests := []struct {
+		name string
+		read func() ([]Label, error)
+	}{
+		{
+			name: "csv",
+			read: func() ([]Label, error) {
+				return ReadLabelsCSV(strings.NewReader("device_id,service_id,start,end\nd1,s1,60,120\nd2,,2025-01-01T00:00:00Z,2025-01-01T01:00:00Z\n"))
+			},
+		},
+		{
+			name: "json lines",
+			read: func() ([]Label, error) {
+				return ReadLabelsJSONLines(strings.NewReader(`{"device_id":"d1","service_id":"s1","start":60,"end":120}` + "\n" +
+					`{"device_id":"d2","start":"2025-01-01T00:00:00Z","end":"2025-01-01T01:00:00Z"}` + "\n"))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.read()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("\n%#v\n%#v", actual, expected)
+			}
+		})
+	}
+
+	t.Run("end before start", func(t *testing.T) {
+		_, err := ReadLabelsCSV(strings.NewReader("device_id,start,end\nd1,120,60\n"))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a recorded value of a device/service
+type Record struct {
+	DeviceId  string      `json:"device_id"`
+	ServiceId string      `json:"service_id,omitempty"`
+	Timestamp int64       `json:"-"` //unix timestamp in seconds
+	Value     interface{} `json:"value"`
+}
+
+// ReadFile reads records from a csv file (.csv) or a json-lines file (any other extension)
+func ReadFile(location string) ([]Record, error) {
+	file, err := os.Open(location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open input: %w", err)
+	}
+	defer file.Close()
+	if strings.EqualFold(filepath.Ext(location), ".csv") {
+		return ReadCSV(file)
+	}
+	return ReadJSONLines(file)
+}
+
+// ReadCSV reads records from csv with a header line
+// the columns device_id, timestamp and value are required, service_id is optional
+// timestamps are unix seconds or RFC3339; values, which are no numbers or booleans, are used as strings
+func ReadCSV(reader io.Reader) (result []Record, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read csv header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"device_id", "timestamp", "value"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing csv column %v", required)
+		}
+	}
+	serviceColumn, hasService := columns["service_id"]
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read csv: %w", err)
+		}
+		record := Record{
+			DeviceId: row[columns["device_id"]],
+			Value:    parseValue(row[columns["value"]]),
+		}
+		if hasService {
+			record.ServiceId = row[serviceColumn]
+		}
+		record.Timestamp, err = parseTimestamp(row[columns["timestamp"]])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp in line %v: %w", line, err)
+		}
+		result = append(result, record)
+	}
+}
+
+// ReadJSONLines reads one json object per line with the fields device_id, service_id (optional), timestamp and value
+// timestamps are unix seconds or RFC3339 strings; empty lines are skipped
+func ReadJSONLines(reader io.Reader) (result []Record, err error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		element := struct {
+			Record
+			Timestamp json.RawMessage `json:"timestamp"`
+		}{}
+		err = json.Unmarshal(scanner.Bytes(), &element)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal line %v: %w", line, err)
+		}
+		element.Record.Timestamp, err = parseTimestamp(rawTimestamp(element.Timestamp))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp in line %v: %w", line, err)
+		}
+		result = append(result, element.Record)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read json lines: %w", err)
+	}
+	return result, nil
+}
+
+func parseTimestamp(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return int64(seconds), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("expected unix seconds or RFC3339, got %#v", value)
+	}
+	return t.Unix(), nil
+}
+
+func parseValue(value string) interface{} {
+	value = strings.TrimSpace(value)
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+// MemoryStore is an in-memory handler.Store
+// values are stored json encoded, to give handlers the same value types as the valkey store (e.g. float64 for numbers)
+type MemoryStore struct {
+	values map[string][]byte
+	mux    sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: map[string][]byte{}}
+}
+
+func (this *MemoryStore) Get(key string, value interface{}) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	buff, ok := this.values[key]
+	if !ok {
+		return fmt.Errorf("unable to get value %v: %w", key, ErrNotFound)
+	}
+	err := json.Unmarshal(buff, value)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal value %v: %w", key, err)
+	}
+	return nil
+}
+
+func (this *MemoryStore) Set(key string, value interface{}) error {
+	buff, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to marshal value %v: %w", key, err)
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.values[key] = buff
+	return nil
+}
@@ -25,20 +25,39 @@ import (
 
 type Config struct {
 	Debug                                bool     `json:"debug" env_var:"DEBUG"`
+	ApiPort                              string   `json:"api_port" env_var:"API_PORT"`
+	MetricsPort                          string   `json:"metrics_port" env_var:"METRICS_PORT"` //internal port of the prometheus /metrics endpoint; "-" to disable
+	EventSource                          string   `json:"event_source" env_var:"EVENT_SOURCE"` //kafka (default) or mqtt; without kafka, set cache_invalidation_kafka_topics to [], device_kafka_topic to "-" and use register_reload_interval
 	KafkaUrl                             string   `json:"kafka_url" env_var:"KAFKA_URL"`
 	KafkaConsumerGroup                   string   `json:"kafka_consumer_group" env_var:"KAFKA_CONSUMER_GROUP"`
+	KafkaConsumerWorkers                 int      `json:"kafka_consumer_workers" env_var:"KAFKA_CONSUMER_WORKERS"`
+	DeadLetterTopic                      string   `json:"dead_letter_topic" env_var:"DEAD_LETTER_TOPIC"`
+	MqttBroker                           string   `json:"mqtt_broker" env_var:"MQTT_BROKER"`
+	MqttClientId                         string   `json:"mqtt_client_id" env_var:"MQTT_CLIENT_ID"`
+	MqttUser                             string   `json:"mqtt_user" env_var:"MQTT_USER"`
+	MqttPw                               string   `json:"mqtt_pw" env_var:"MQTT_PW"`
+	MqttQos                              byte     `json:"mqtt_qos" env_var:"MQTT_QOS"`
+	MqttTopicPrefix                      string   `json:"mqtt_topic_prefix" env_var:"MQTT_TOPIC_PREFIX"` //subscribed topics are <prefix><service id with "#" and ":" replaced by "_">; payloads must use the kafka device event format (see consumer.ManagedMqttSubscriber)
 	ValKeyUrl                            string   `json:"val_key_url" env_var:"VAL_KEY_URL"`
 	DeviceRepositoryUrl                  string   `json:"device_repository_url" env_var:"DEVICE_REPOSITORY_URL"`
 	DeviceSelectionUrl                   string   `json:"device_selection_url" env_var:"DEVICE_SELECTION_URL"`
 	CacheInvalidationKafkaTopics         []string `json:"cache_invalidation_kafka_topics" env_var:"CACHE_INVALIDATION_KAFKA_TOPICS"`
+	DeviceKafkaTopic                     string   `json:"device_kafka_topic" env_var:"DEVICE_KAFKA_TOPIC"`
+	RegisterReloadInterval               string   `json:"register_reload_interval" env_var:"REGISTER_RELOAD_INTERVAL"` //"-" to only reload on cache invalidations; replaces the kafka cache invalidation in deployments without kafka
 	CacheDuration                        string   `json:"cache_duration" env_var:"CACHE_DURATION"`
 	NotificationUrl                      string   `json:"notification_url" env_var:"NOTIFICATION_URL"`
 	NotificationTopic                    string   `json:"notification_topic" env_var:"NOTIFICATION_TOPIC"`
 	NotificationsIgnoreDuplicatesWithinS int64    `json:"notifications_ignore_duplicates_within_seconds" env_var:"NOTIFICATIONS_IGNORE_DUPLICATES_WITHIN_SECONDS"`
+	NotifyOnResolve                      bool     `json:"notify_on_resolve" env_var:"NOTIFY_ON_RESOLVE"`
 	MongoUrl                             string   `json:"mongo_url" env_var:"MONGO_URL"`
 	MongoTable                           string   `json:"mongo_table" env_var:"MONGO_TABLE"`
 	MongoAnomalyCollection               string   `json:"mongo_anomaly_collection" env_var:"MONGO_ANOMALY_COLLECTION"`
 	AnomalyDetectorAttribute             string   `json:"anomaly_detector_attribute" env_var:"ANOMALY_DETECTOR_ATTRIBUTE"`
+	SilentDeviceTimeout                  string   `json:"silent_device_timeout" env_var:"SILENT_DEVICE_TIMEOUT"` //default timeout of devices without <attr>:silent_device:timeout attribute; "-" to only watch devices with this attribute
+	SilentDeviceCheckInterval            string   `json:"silent_device_check_interval" env_var:"SILENT_DEVICE_CHECK_INTERVAL"`
+	HandlerConfigLocation                string   `json:"handler_config_location" env_var:"HANDLER_CONFIG_LOCATION"`
+	FailureBudget                        int      `json:"failure_budget" env_var:"FAILURE_BUDGET"`
+	FailureBudgetWindow                  string   `json:"failure_budget_window" env_var:"FAILURE_BUDGET_WINDOW"`
 }
 
 func Load(location string) (conf Config, err error) {
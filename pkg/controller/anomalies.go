@@ -0,0 +1,141 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	devicerepo "github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ListAnomalies returns the stored anomalies matching the query
+// non admin users only receive anomalies of devices they own
+func (this *Controller) ListAnomalies(token string, query anomalystore.AnomalyQuery) (result []anomalystore.Anomaly, total int64, err error, code int) {
+	parsedToken, err := jwt.Parse(token)
+	if err != nil {
+		return nil, 0, err, http.StatusUnauthorized
+	}
+	if !parsedToken.IsAdmin() {
+		query.Devices, err, code = this.listOwnedDeviceIds(parsedToken)
+		if err != nil {
+			return nil, 0, err, code
+		}
+	}
+	result, total, err = this.anomalyStore.ListAnomalies(query)
+	if errors.Is(err, anomalystore.ErrInvalidSort) {
+		return nil, 0, err, http.StatusBadRequest
+	}
+	if err != nil {
+		log.Println("ERROR: unable to list anomalies", err)
+		return nil, 0, err, http.StatusInternalServerError
+	}
+	return result, total, nil, http.StatusOK
+}
+
+// GetAnomaly returns the anomaly if it belongs to a device owned by the requesting user
+func (this *Controller) GetAnomaly(token string, id string) (result anomalystore.Anomaly, err error, code int) {
+	parsedToken, err := jwt.Parse(token)
+	if err != nil {
+		return result, err, http.StatusUnauthorized
+	}
+	return this.getOwnedAnomaly(parsedToken, id)
+}
+
+func (this *Controller) getOwnedAnomaly(token jwt.Token, id string) (result anomalystore.Anomaly, err error, code int) {
+	result, err = this.anomalyStore.GetAnomaly(id)
+	if errors.Is(err, anomalystore.ErrNotFound) {
+		return result, err, http.StatusNotFound
+	}
+	if err != nil {
+		log.Println("ERROR: unable to get anomaly", err)
+		return result, err, http.StatusInternalServerError
+	}
+	err, code = this.checkDeviceOwnership(token, result.Device)
+	if err != nil {
+		return anomalystore.Anomaly{}, err, code
+	}
+	return result, nil, http.StatusOK
+}
+
+// SetAnomalyStatus acknowledges or resolves an anomaly of a device owned by the requesting user
+func (this *Controller) SetAnomalyStatus(token string, id string, status anomalystore.Status) (result anomalystore.Anomaly, err error, code int) {
+	parsedToken, err := jwt.Parse(token)
+	if err != nil {
+		return result, err, http.StatusUnauthorized
+	}
+	_, err, code = this.getOwnedAnomaly(parsedToken, id)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.anomalyStore.SetAnomalyStatus(id, status, parsedToken.GetUserId(), time.Now().Unix())
+	switch {
+	case errors.Is(err, anomalystore.ErrNotFound):
+		return result, err, http.StatusNotFound
+	case errors.Is(err, anomalystore.ErrInvalidStatus):
+		return result, err, http.StatusBadRequest
+	case errors.Is(err, anomalystore.ErrInvalidStatusTransition):
+		return result, err, http.StatusConflict
+	case err != nil:
+		log.Println("ERROR: unable to set anomaly status", err)
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) checkDeviceOwnership(token jwt.Token, deviceId string) (err error, code int) {
+	if token.IsAdmin() {
+		return nil, http.StatusOK
+	}
+	device, err, code := this.deviceRepoClient.ReadDevice(deviceId, token.Jwt(), devicerepo.READ)
+	if err != nil {
+		return err, code
+	}
+	if device.OwnerId != token.GetUserId() {
+		return errors.New("access denied"), http.StatusForbidden
+	}
+	return nil, http.StatusOK
+}
+
+const deviceListBatchSize = 1000
+
+func (this *Controller) listOwnedDeviceIds(token jwt.Token) (result []string, err error, code int) {
+	result = []string{}
+	for offset := int64(0); ; offset += deviceListBatchSize {
+		devices, err, code := this.deviceRepoClient.ListDevices(token.Jwt(), devicerepo.DeviceListOptions{
+			Limit:      deviceListBatchSize,
+			Offset:     offset,
+			SortBy:     "name.asc",
+			Permission: devicerepo.READ,
+		})
+		if err != nil {
+			log.Println("ERROR: unable to list devices of user", token.GetUserId(), err)
+			return nil, err, code
+		}
+		for _, device := range devices {
+			if device.OwnerId == token.GetUserId() {
+				result = append(result, device.Id)
+			}
+		}
+		if len(devices) < deviceListBatchSize {
+			return result, nil, http.StatusOK
+		}
+	}
+}
@@ -17,33 +17,365 @@
 package anomalystore
 
 import (
+	"context"
+	"errors"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"slices"
+	"strings"
+	"time"
 )
 
+type Status string
+
+const (
+	StatusOpen         Status = "open"
+	StatusAcknowledged Status = "acknowledged"
+	StatusResolved     Status = "resolved"
+)
+
+func (this Status) Validate() error {
+	switch this {
+	case StatusOpen, StatusAcknowledged, StatusResolved:
+		return nil
+	default:
+		return ErrInvalidStatus
+	}
+}
+
+// allowedPreviousStatus lists for each status from which status it may be reached
+var allowedPreviousStatus = map[Status][]Status{
+	StatusAcknowledged: {StatusOpen},
+	StatusResolved:     {StatusOpen, StatusAcknowledged},
+}
+
 type Anomaly struct {
-	Handler       string `json:"handler" bson:"handler"`
-	Device        string `json:"device" bson:"device"`
-	Service       string `json:"service" bson:"service"`
-	Description   string `json:"description" bson:"description"`
+	Id                string           `json:"id" bson:"id"`
+	Handler           string           `json:"handler" bson:"handler"`
+	Device            string           `json:"device" bson:"device"`
+	Service           string           `json:"service" bson:"service"`
+	Description       string           `json:"description" bson:"description"`                 //of the latest detection
+	Score             float64          `json:"score" bson:"score"`                             //of the latest detection
+	Severity          handler.Severity `json:"severity" bson:"severity"`                       //of the latest detection
+	UnixTimestamp     int64            `json:"unix_timestamp" bson:"unix_timestamp"`           //first detection
+	LastUnixTimestamp int64            `json:"last_unix_timestamp" bson:"last_unix_timestamp"` //latest detection
+	Detections        int64            `json:"detections" bson:"detections"`
+	Status            Status           `json:"status" bson:"status"`
+	Shadow            bool             `json:"shadow" bson:"shadow"` //detected by a handler in shadow mode; device owners are not notified
+	StatusHistory     []StatusChange   `json:"status_history" bson:"status_history"`
+}
+
+type StatusChange struct {
+	Status        Status `json:"status" bson:"status"`
 	UnixTimestamp int64  `json:"unix_timestamp" bson:"unix_timestamp"`
+	User          string `json:"user" bson:"user"` //empty if the change was triggered by the service itself
 }
 
 var AnomalyBson = getBsonFieldObject[Anomaly]()
 
+// bson field names, which are not available as plain strings in AnomalyBson
+const (
+	UnixTimestampBson     = "unix_timestamp"
+	LastUnixTimestampBson = "last_unix_timestamp"
+	DetectionsBson        = "detections"
+	StatusBson            = "status"
+	StatusHistoryBson     = "status_history"
+	ScoreBson             = "score"
+	SeverityBson          = "severity"
+	ShadowBson            = "shadow"
+)
+
+var ErrNotFound = errors.New("anomaly not found")
+var ErrInvalidStatus = errors.New("invalid status; expected open, acknowledged or resolved")
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.anomalyCollection()
+		err := db.ensureIndex(collection, "anomaly_device_index", AnomalyBson.Device, true, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "anomaly_unix_timestamp_index", UnixTimestampBson, true, false)
+		if err != nil {
+			return err
+		}
+		err = db.migrateLegacyAnomalies()
+		if err != nil {
+			return err
+		}
+		//partial, to not fail on documents without id of instances, which have not been migrated yet
+		err = db.ensurePartialIndex(collection, "anomaly_id_partial_index", AnomalyBson.Id, true, true, bson.M{AnomalyBson.Id: bson.M{"$exists": true}})
+		if err != nil {
+			return err
+		}
+		return db.ensureCompoundIndex(collection, "anomaly_handler_device_service_index", true, false, AnomalyBson.Handler, AnomalyBson.Device, AnomalyBson.Service)
+	})
+}
+
+type AnomalyQuery struct {
+	Devices  []string         //filter; ignored if nil; Devices == []string{} will return an empty list
+	Device   string           //filter; ignored if empty
+	Service  string           //filter; ignored if empty
+	Handler  string           //filter; ignored if empty
+	Status   Status           //filter; ignored if empty
+	Severity handler.Severity //filter; ignored if empty
+	Shadow   bool             //filter; if true only anomalies of handlers in shadow mode are listed, otherwise only the others
+	MinScore float64          //filter; ignored if 0
+	From     int64            //filter; unix timestamp in seconds; ignored if 0
+	To       int64            //filter; unix timestamp in seconds; ignored if 0
+	Limit    int64            //default 100
+	Offset   int64            //default 0
+	Sort     string           //default unix_timestamp.desc
+}
+
+var ErrInvalidSort = errors.New("invalid sort; expected unix_timestamp.asc or unix_timestamp.desc")
+
+// sortDirection returns the mongo sort direction of AnomalyQuery.Sort; unix_timestamp without direction is sorted descending
+func sortDirection(sort string) (int, error) {
+	if sort == "" {
+		return -1, nil
+	}
+	sortField, direction, _ := strings.Cut(sort, ".")
+	if sortField != UnixTimestampBson {
+		return 0, ErrInvalidSort
+	}
+	switch direction {
+	case "asc":
+		return 1, nil
+	case "", "desc":
+		return -1, nil
+	default:
+		return 0, ErrInvalidSort
+	}
+}
+
 func (this *Mongo) anomalyCollection() *mongo.Collection {
 	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoAnomalyCollection)
 }
 
-func (this *Mongo) StoreAnomaly(handlerName string, deviceId string, serviceId string, desc string, timestamp int64) error {
-	_, err := this.anomalyCollection().InsertOne(getTimeoutContext(), Anomaly{
-		Handler:       handlerName,
-		Device:        deviceId,
-		Service:       serviceId,
-		Description:   desc,
-		UnixTimestamp: timestamp,
-	})
+// StoreAnomaly attaches the detection to the not resolved anomaly of the handler/device/service
+// or creates a new open anomaly, if none exists
+// shadow anomalies (of handlers in shadow mode) are kept apart from the others, to not mix them if the mode of a handler changes
+func (this *Mongo) StoreAnomaly(handlerName string, deviceId string, serviceId string, desc string, score float64, severity handler.Severity, timestamp int64, shadow bool) error {
+	filter := bson.M{
+		AnomalyBson.Handler: handlerName,
+		AnomalyBson.Device:  deviceId,
+		AnomalyBson.Service: serviceId,
+		StatusBson:          bson.M{"$exists": true, "$ne": StatusResolved},
+		ShadowBson:          shadowFilter(shadow),
+	}
+	update := bson.M{
+		"$set": bson.M{
+			AnomalyBson.Description: desc,
+			ScoreBson:               score,
+			SeverityBson:            severity,
+			LastUnixTimestampBson:   timestamp,
+		},
+		"$inc": bson.M{
+			DetectionsBson: 1,
+		},
+		"$setOnInsert": bson.M{
+			AnomalyBson.Id:    uuid.NewString(),
+			UnixTimestampBson: timestamp,
+			StatusBson:        StatusOpen,
+			StatusHistoryBson: []StatusChange{{Status: StatusOpen, UnixTimestamp: timestamp}},
+			ShadowBson:        shadow,
+		},
+	}
+	_, err := this.anomalyCollection().UpdateOne(getTimeoutContext(), filter, update, options.Update().SetUpsert(true))
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// ResolveAnomaly resolves the not resolved anomaly of the handler/device/service
+// like in StoreAnomaly, shadow anomalies are only resolved by handlers in shadow mode and the others only by handlers in other modes
+// returns resolved = false if no such anomaly exists
+func (this *Mongo) ResolveAnomaly(handlerName string, deviceId string, serviceId string, timestamp int64, shadow bool) (resolved bool, err error) {
+	result, err := this.anomalyCollection().UpdateOne(
+		getTimeoutContext(),
+		bson.M{
+			AnomalyBson.Handler: handlerName,
+			AnomalyBson.Device:  deviceId,
+			AnomalyBson.Service: serviceId,
+			StatusBson:          bson.M{"$exists": true, "$ne": StatusResolved},
+			ShadowBson:          shadowFilter(shadow),
+		},
+		bson.M{
+			"$set": bson.M{StatusBson: StatusResolved},
+			"$push": bson.M{
+				StatusHistoryBson: StatusChange{Status: StatusResolved, UnixTimestamp: timestamp},
+			},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// migrateLegacyAnomalies backfills the fields of the anomaly lifecycle on documents, which have been stored before it existed.
+// such documents are single detections without id; they are migrated as anomalies with one detection, which are already resolved,
+// to not report historical detections as open anomalies.
+func (this *Mongo) migrateLegacyAnomalies() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	collection := this.anomalyCollection()
+	cursor, err := collection.Find(ctx, bson.M{AnomalyBson.Id: bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{"_id": 1, UnixTimestampBson: 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	count := 0
+	for cursor.Next(ctx) {
+		legacy := struct {
+			ObjectId      interface{} `bson:"_id"`
+			UnixTimestamp int64       `bson:"unix_timestamp"`
+		}{}
+		err = cursor.Decode(&legacy)
+		if err != nil {
+			return err
+		}
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": legacy.ObjectId, AnomalyBson.Id: bson.M{"$exists": false}}, bson.M{
+			"$set": bson.M{
+				AnomalyBson.Id:        uuid.NewString(),
+				LastUnixTimestampBson: legacy.UnixTimestamp,
+				DetectionsBson:        1,
+				StatusBson:            StatusResolved,
+				StatusHistoryBson: []StatusChange{
+					{Status: StatusOpen, UnixTimestamp: legacy.UnixTimestamp},
+					{Status: StatusResolved, UnixTimestamp: legacy.UnixTimestamp},
+				},
+				ShadowBson: false,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	if count > 0 {
+		log.Printf("migrated %v legacy anomalies\n", count)
+	}
+	return cursor.Err()
+}
+
+func (this *Mongo) GetAnomaly(id string) (result Anomaly, err error) {
+	err = this.anomalyCollection().FindOne(getTimeoutContext(), bson.M{AnomalyBson.Id: id}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return result, ErrNotFound
+	}
+	return result, err
+}
+
+// SetAnomalyStatus changes the status of the anomaly and records the change in its status history
+// returns ErrInvalidStatusTransition if the anomaly is not in a status from which the new status may be reached
+func (this *Mongo) SetAnomalyStatus(id string, status Status, user string, timestamp int64) (result Anomaly, err error) {
+	allowed, ok := allowedPreviousStatus[status]
+	if !ok {
+		return result, ErrInvalidStatus
+	}
+	err = this.anomalyCollection().FindOneAndUpdate(
+		getTimeoutContext(),
+		bson.M{
+			AnomalyBson.Id: id,
+			StatusBson:     bson.M{"$in": allowed},
+		},
+		bson.M{
+			"$set": bson.M{StatusBson: status},
+			"$push": bson.M{
+				StatusHistoryBson: StatusChange{Status: status, UnixTimestamp: timestamp, User: user},
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		_, err = this.GetAnomaly(id)
+		if err != nil {
+			return result, err
+		}
+		return result, ErrInvalidStatusTransition
+	}
+	return result, err
+}
+
+func (this *Mongo) ListAnomalies(query AnomalyQuery) (result []Anomaly, total int64, err error) {
+	filter := bson.M{}
+	if query.Devices != nil {
+		filter[AnomalyBson.Device] = bson.M{"$in": query.Devices}
+	}
+	if query.Device != "" {
+		if query.Devices != nil && !slices.Contains(query.Devices, query.Device) {
+			return []Anomaly{}, 0, nil
+		}
+		filter[AnomalyBson.Device] = query.Device
+	}
+	if query.Service != "" {
+		filter[AnomalyBson.Service] = query.Service
+	}
+	if query.Handler != "" {
+		filter[AnomalyBson.Handler] = query.Handler
+	}
+	if query.Status != "" {
+		filter[StatusBson] = query.Status
+	}
+	if query.Severity != "" {
+		filter[SeverityBson] = query.Severity
+	}
+	filter[ShadowBson] = shadowFilter(query.Shadow)
+	if query.MinScore != 0 {
+		filter[ScoreBson] = bson.M{"$gte": query.MinScore}
+	}
+	timeFilter := bson.M{}
+	if query.From != 0 {
+		timeFilter["$gte"] = query.From
+	}
+	if query.To != 0 {
+		timeFilter["$lte"] = query.To
+	}
+	if len(timeFilter) > 0 {
+		filter[UnixTimestampBson] = timeFilter
+	}
+
+	direction, err := sortDirection(query.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	opt := options.Find().
+		SetLimit(query.Limit).
+		SetSkip(query.Offset).
+		SetSort(bson.D{{Key: UnixTimestampBson, Value: direction}})
+
+	ctx := getTimeoutContext()
+	total, err = this.anomalyCollection().CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err := this.anomalyCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return nil, 0, err
+	}
+	result = []Anomaly{}
+	err = cursor.All(ctx, &result)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// shadowFilter matches anomalies stored before the shadow field existed as not shadow
+func shadowFilter(shadow bool) interface{} {
+	if shadow {
+		return true
+	}
+	return bson.M{"$ne": true}
+}
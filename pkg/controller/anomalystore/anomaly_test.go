@@ -0,0 +1,48 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package anomalystore
+
+import (
+	"testing"
+)
+
+func TestSortDirection(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    int
+		wantErr bool
+	}{
+		{sort: "", want: -1},
+		{sort: "unix_timestamp", want: -1},
+		{sort: "unix_timestamp.desc", want: -1},
+		{sort: "unix_timestamp.asc", want: 1},
+		{sort: "unix_timestamp.up", wantErr: true},
+		{sort: "score.asc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			got, err := sortDirection(tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sortDirection() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("sortDirection() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
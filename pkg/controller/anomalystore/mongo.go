@@ -21,6 +21,7 @@ import (
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
@@ -44,8 +45,9 @@ func getTimeoutContext() context.Context {
 	return ctx
 }
 
-func New(conf configuration.Config) (*Mongo, error) {
-	c, err := mongo.Connect(getTimeoutContext(), options.Client().ApplyURI(conf.MongoUrl), options.Client().SetReadConcern(readconcern.Majority()))
+// New connects to the mongodb; monitor is optional and may be used to observe commands (e.g. for metrics)
+func New(conf configuration.Config, monitor *event.CommandMonitor) (*Mongo, error) {
+	c, err := mongo.Connect(getTimeoutContext(), options.Client().ApplyURI(conf.MongoUrl), options.Client().SetReadConcern(readconcern.Majority()), options.Client().SetMonitor(monitor))
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +62,12 @@ func New(conf configuration.Config) (*Mongo, error) {
 	return db, nil
 }
 
+func (this *Mongo) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return this.client.Ping(ctx, nil)
+}
+
 func (this *Mongo) Disconnect() {
 	timeout, _ := context.WithTimeout(context.Background(), 10*time.Second)
 	log.Println(this.client.Disconnect(timeout))
@@ -150,6 +158,19 @@ func (this *Mongo) ensureIndex(collection *mongo.Collection, indexname string, i
 	return err
 }
 
+// ensurePartialIndex creates an index, which only contains the documents matching the filter
+func (this *Mongo) ensurePartialIndex(collection *mongo.Collection, indexname string, indexKey string, asc bool, unique bool, filter bson.M) error {
+	var direction int32 = -1
+	if asc {
+		direction = 1
+	}
+	_, err := collection.Indexes().CreateOne(getTimeoutContext(), mongo.IndexModel{
+		Keys:    bson.D{{Key: indexKey, Value: direction}},
+		Options: options.Index().SetName(indexname).SetUnique(unique).SetPartialFilterExpression(filter),
+	})
+	return err
+}
+
 func (this *Mongo) ensureCompoundIndex(collection *mongo.Collection, indexname string, asc bool, unique bool, indexKeys ...string) error {
 	var direction int32 = -1
 	if asc {
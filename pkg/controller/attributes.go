@@ -0,0 +1,110 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/models/go/models"
+	"slices"
+	"strings"
+)
+
+// DeviceSettings are the per device handler settings, read from device attributes
+// (<attr> = config.AnomalyDetectorAttribute, <handler> = name or type of a handler entry):
+//
+//	key: <attr>                      value: true            enables anomaly detection for the device
+//	key: <attr>:disabled             value: <handler>,...   disables the listed handlers for the device
+//	key: <attr>:enabled              value: <handler>,...   only the listed handlers are used for the device
+//	key: <attr>:<handler>:<param>    value: <value>         overrides a parameter of the handler for the device
+//	key: <attr>:silent_device:timeout value: <duration>     reports a silent_device anomaly, if the device sends no event within the duration
+type DeviceSettings struct {
+	Enabled    bool
+	Only       []string //nil if all handlers are enabled
+	Disabled   []string
+	Parameters map[string]map[string]interface{} //handler name or type -> parameter name -> raw value
+}
+
+func ParseDeviceSettings(attributeKey string, attributes []models.Attribute) (result DeviceSettings) {
+	prefix := attributeKey + ":"
+	for _, attr := range attributes {
+		value := strings.TrimSpace(attr.Value)
+		switch {
+		case attr.Key == attributeKey:
+			result.Enabled = strings.ToLower(value) == "true"
+		case attr.Key == prefix+"disabled":
+			result.Disabled = append(result.Disabled, splitHandlerList(value)...)
+		case attr.Key == prefix+"enabled":
+			result.Only = append(result.Only, splitHandlerList(value)...)
+		case strings.HasPrefix(attr.Key, prefix):
+			handlerRef, parameter, ok := strings.Cut(strings.TrimPrefix(attr.Key, prefix), ":")
+			if !ok || handlerRef == "" || parameter == "" {
+				continue
+			}
+			if result.Parameters == nil {
+				result.Parameters = map[string]map[string]interface{}{}
+			}
+			if result.Parameters[handlerRef] == nil {
+				result.Parameters[handlerRef] = map[string]interface{}{}
+			}
+			result.Parameters[handlerRef][parameter] = value
+		}
+	}
+	return result
+}
+
+func splitHandlerList(value string) (result []string) {
+	for _, element := range strings.Split(value, ",") {
+		element = strings.TrimSpace(element)
+		if element != "" {
+			result = append(result, element)
+		}
+	}
+	return result
+}
+
+func matchesHandler(refs []string, entry handler.Entry) bool {
+	return slices.Contains(refs, entry.Name) || (entry.Type != "" && slices.Contains(refs, entry.Type))
+}
+
+func (this DeviceSettings) IsHandlerEnabled(entry handler.Entry) bool {
+	if !this.Enabled {
+		return false
+	}
+	if this.Only != nil && !matchesHandler(this.Only, entry) {
+		return false
+	}
+	return !matchesHandler(this.Disabled, entry)
+}
+
+// HandlerParameters returns the parameters of the entry with the overrides of the device applied
+// overrides by handler type are applied before overrides by handler name
+// changed is false if the device has no overrides for the entry
+func (this DeviceSettings) HandlerParameters(entry handler.Entry) (result handler.Parameters, changed bool, err error) {
+	result = entry.Parameters
+	for _, ref := range []string{entry.Type, entry.Name} {
+		overrides, ok := this.Parameters[ref]
+		if ref == "" || !ok {
+			continue
+		}
+		result, err = entry.ParameterDefinitions().Apply(result, overrides)
+		if err != nil {
+			return entry.Parameters, false, err
+		}
+		changed = true
+	}
+	return result, changed, nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/models/go/models"
+	"reflect"
+	"testing"
+)
+
+func TestDeviceSettings(t *testing.T) {
+	register := handler.NewRegister()
+	err := register.Register("big_jump_water", "f", "a", "c", 2, handler.BigJumpHandler{}, handler.WithType(handler.BigJumpType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = register.Register("jump_back_water", "f", "a", "c", 2, handler.JumpBackHandler{}, handler.WithType(handler.JumpBackType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := register.List()
+	bigJump, jumpBack := entries[0], entries[1]
+	if bigJump.Name != "big_jump_water" {
+		bigJump, jumpBack = jumpBack, bigJump
+	}
+
+	type want struct {
+		bigJumpEnabled  bool
+		jumpBackEnabled bool
+		bigJumpParams   handler.Parameters
+		changed         bool
+		wantErr         bool
+	}
+	tests := []struct {
+		name       string
+		attributes []models.Attribute
+		want       want
+	}{
+		{
+			name:       "not enabled",
+			attributes: []models.Attribute{{Key: "anomaly-detector", Value: "false"}},
+			want:       want{bigJumpParams: handler.Parameters{"sigma": 5.0, "critical_sigma": 10.0}},
+		},
+		{
+			name:       "enabled",
+			attributes: []models.Attribute{{Key: "anomaly-detector", Value: " True "}},
+			want:       want{bigJumpEnabled: true, jumpBackEnabled: true, bigJumpParams: handler.Parameters{"sigma": 5.0, "critical_sigma": 10.0}},
+		},
+		{
+			name: "disabled by type",
+			attributes: []models.Attribute{
+				{Key: "anomaly-detector", Value: "true"},
+				{Key: "anomaly-detector:disabled", Value: "jump_back"},
+			},
+			want: want{bigJumpEnabled: true, bigJumpParams: handler.Parameters{"sigma": 5.0, "critical_sigma": 10.0}},
+		},
+		{
+			name: "enabled by name",
+			attributes: []models.Attribute{
+				{Key: "anomaly-detector", Value: "true"},
+				{Key: "anomaly-detector:enabled", Value: "foo, jump_back_water"},
+			},
+			want: want{jumpBackEnabled: true, bigJumpParams: handler.Parameters{"sigma": 5.0, "critical_sigma": 10.0}},
+		},
+		{
+			name: "parameter by type",
+			attributes: []models.Attribute{
+				{Key: "anomaly-detector", Value: "true"},
+				{Key: "anomaly-detector:big_jump:sigma", Value: "4"},
+			},
+			want: want{bigJumpEnabled: true, jumpBackEnabled: true, bigJumpParams: handler.Parameters{"sigma": 4.0, "critical_sigma": 10.0}, changed: true},
+		},
+		{
+			name: "parameter by name wins",
+			attributes: []models.Attribute{
+				{Key: "anomaly-detector", Value: "true"},
+				{Key: "anomaly-detector:big_jump_water:sigma", Value: "3"},
+				{Key: "anomaly-detector:big_jump:sigma", Value: "4"},
+			},
+			want: want{bigJumpEnabled: true, jumpBackEnabled: true, bigJumpParams: handler.Parameters{"sigma": 3.0, "critical_sigma": 10.0}, changed: true},
+		},
+		{
+			name: "invalid parameter",
+			attributes: []models.Attribute{
+				{Key: "anomaly-detector", Value: "true"},
+				{Key: "anomaly-detector:big_jump:sigma", Value: "-4"},
+			},
+			want: want{bigJumpEnabled: true, jumpBackEnabled: true, bigJumpParams: handler.Parameters{"sigma": 5.0, "critical_sigma": 10.0}, wantErr: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := ParseDeviceSettings("anomaly-detector", tt.attributes)
+			if got := settings.IsHandlerEnabled(bigJump); got != tt.want.bigJumpEnabled {
+				t.Errorf("IsHandlerEnabled(big_jump) = %v, want %v", got, tt.want.bigJumpEnabled)
+			}
+			if got := settings.IsHandlerEnabled(jumpBack); got != tt.want.jumpBackEnabled {
+				t.Errorf("IsHandlerEnabled(jump_back) = %v, want %v", got, tt.want.jumpBackEnabled)
+			}
+			params, changed, err := settings.HandlerParameters(bigJump)
+			if (err != nil) != tt.want.wantErr {
+				t.Errorf("HandlerParameters() error = %v, wantErr %v", err, tt.want.wantErr)
+			}
+			if changed != tt.want.changed {
+				t.Errorf("HandlerParameters() changed = %v, want %v", changed, tt.want.changed)
+			}
+			if !reflect.DeepEqual(params, tt.want.bigJumpParams) {
+				t.Errorf("HandlerParameters() = %#v, want %#v", params, tt.want.bigJumpParams)
+			}
+		})
+	}
+}
@@ -36,13 +36,22 @@ func (this *Controller) updateConsumer(serviceIds []string) error {
 }
 
 func (this *Controller) HandleConsumerMessage(msg model.ConsumerMessage) (err error) {
+	this.metrics.EventsConsumed.WithLabelValues(msg.Topic).Inc()
+	event, err := parseConsumerMessage(msg)
+	if err != nil {
+		return err
+	}
+	return this.Send(event)
+}
+
+func parseConsumerMessage(msg model.ConsumerMessage) (result model.EventMessageWithTimestamp, err error) {
 	event := model.EventMessage{}
 	err = json.Unmarshal(msg.Message, &event)
 	if err != nil {
 		err = fmt.Errorf("unable to unmarshal event: err=%#v msg=%#v", err.Error(), string(msg.Message))
 		log.Println("ERROR:", err)
 		err = errors.Join(err, model.ErrWillBeIgnored)
-		return err
+		return result, err
 	}
 	eventWithTimestamp := model.EventMessageWithTimestamp{
 		EventMessage: event,
@@ -51,5 +60,5 @@ func (this *Controller) HandleConsumerMessage(msg model.ConsumerMessage) (err er
 	if eventWithTimestamp.Timestamp == 0 {
 		eventWithTimestamp.Timestamp = time.Now().Unix()
 	}
-	return this.Send(eventWithTimestamp)
+	return eventWithTimestamp, nil
 }
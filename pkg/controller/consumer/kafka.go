@@ -30,7 +30,15 @@ import (
 	"time"
 )
 
-func StartKafkaLastOffsetConsumerGroup(ctx context.Context, wg *sync.WaitGroup, broker string, groupId string, topics []string, listener func(msg model.ConsumerMessage) error, errhandler func(topic string, err error)) error {
+/* Count of fetched messages per worker, which may wait for processing*/
+const workerQueueSize = 100
+
+// StartKafkaLastOffsetConsumerGroup consumes the topics with the given count of parallel workers
+// messages with the same topic and key are passed to the listener in order;
+// offsets are committed when the message and all earlier messages of the partition are handled
+// messages, which still fail after 10 minutes of retries, are passed to failed (optional) and skipped, to not block the commits of the partition.
+// retries stop when ctx is done; the message is not committed in this case and will be consumed again
+func StartKafkaLastOffsetConsumerGroup(ctx context.Context, wg *sync.WaitGroup, broker string, groupId string, topics []string, workers int, listener func(msg model.ConsumerMessage) error, errhandler func(topic string, err error), failed func(msg model.ConsumerMessage, err error)) error {
 	if len(topics) == 0 {
 		return nil
 	}
@@ -53,6 +61,10 @@ func StartKafkaLastOffsetConsumerGroup(ctx context.Context, wg *sync.WaitGroup,
 		PartitionWatchInterval: time.Minute,
 	})
 
+	tracker := newOffsetTracker(func(msg kafka.Message) error {
+		return r.CommitMessages(ctx, msg)
+	})
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -64,6 +76,35 @@ func StartKafkaLastOffsetConsumerGroup(ctx context.Context, wg *sync.WaitGroup,
 				log.Println("close consumer for topics ", topics)
 			}
 		}()
+		pool := newWorkerPool(workers, workerQueueSize, func(m kafka.Message) {
+			msg := model.ConsumerMessage{
+				Topic:     m.Topic,
+				Key:       string(m.Key),
+				Message:   m.Value,
+				Timestamp: m.Time.Unix(),
+			}
+			err := retry(ctx, func() error {
+				return listener(msg)
+			}, func(n int64) time.Duration {
+				return time.Duration(n) * time.Second
+			}, 10*time.Minute)
+
+			if err != nil && ctx.Err() != nil {
+				return //stopped; not committed
+			}
+			if err != nil {
+				log.Println("ERROR: unable to handle message -> skip", m.Topic, m.Partition, m.Offset, err)
+				errhandler(m.Topic, err)
+				if failed != nil {
+					failed(msg, err)
+				}
+			}
+			err = tracker.Done(m)
+			if err != nil && ctx.Err() == nil {
+				log.Println("ERROR: unable to commit message", m.Topic, m.Partition, m.Offset, err)
+			}
+		})
+		defer pool.Close()
 		for {
 			select {
 			case <-ctx.Done():
@@ -79,30 +120,16 @@ func StartKafkaLastOffsetConsumerGroup(ctx context.Context, wg *sync.WaitGroup,
 					errhandler(topic, err)
 					return
 				}
-
-				err = retry(func() error {
-					return listener(model.ConsumerMessage{
-						Topic:     topic,
-						Message:   m.Value,
-						Timestamp: m.Time.Unix(),
-					})
-				}, func(n int64) time.Duration {
-					return time.Duration(n) * time.Second
-				}, 10*time.Minute)
-
-				if err != nil {
-					log.Println("ERROR: unable to handle message (no commit)", err)
-					errhandler(topic, err)
-				} else {
-					err = r.CommitMessages(ctx, m)
-				}
+				tracker.Add(m)
+				pool.Add(m)
 			}
 		}
 	}()
 	return nil
 }
 
-func retry(f func() error, waitProvider func(n int64) time.Duration, timeout time.Duration) (err error) {
+// retry calls f until it succeeds, the timeout is reached or ctx is done
+func retry(ctx context.Context, f func() error, waitProvider func(n int64) time.Duration, timeout time.Duration) (err error) {
 	err = errors.New("initial")
 	start := time.Now()
 	for i := int64(1); err != nil && time.Since(start) < timeout; i++ {
@@ -110,12 +137,15 @@ func retry(f func() error, waitProvider func(n int64) time.Duration, timeout tim
 		if err != nil {
 			log.Println("ERROR: kafka listener error:", err)
 			wait := waitProvider(i)
-			if time.Since(start)+wait < timeout {
-				log.Println("ERROR: retry after:", wait.String())
-				time.Sleep(wait)
-			} else {
+			if time.Since(start)+wait >= timeout {
 				return err
 			}
+			log.Println("ERROR: retry after:", wait.String())
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(wait):
+			}
 		}
 	}
 	return err
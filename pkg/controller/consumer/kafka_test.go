@@ -0,0 +1,70 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	failing := errors.New("failing")
+	tests := []struct {
+		name      string
+		failures  int
+		timeout   time.Duration
+		cancel    time.Duration //cancels the context after this duration, if set
+		wantErr   bool
+		wantCalls int //minimal count of calls, if the retries depend on timing
+	}{
+		{name: "success", failures: 0, timeout: time.Second, wantCalls: 1},
+		{name: "success after retries", failures: 2, timeout: time.Second, wantCalls: 3},
+		{name: "timeout", failures: 100, timeout: 45 * time.Millisecond, wantErr: true, wantCalls: 2},
+		{name: "canceled", failures: 100, timeout: time.Hour, cancel: 25 * time.Millisecond, wantErr: true, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel > 0 {
+				time.AfterFunc(tt.cancel, cancel)
+			}
+			calls := 0
+			start := time.Now()
+			err := retry(ctx, func() error {
+				calls++
+				if calls <= tt.failures {
+					return failing
+				}
+				return nil
+			}, func(n int64) time.Duration {
+				return 10 * time.Millisecond
+			}, tt.timeout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("retry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls && (!tt.wantErr || calls < tt.wantCalls) {
+				t.Errorf("retry() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if time.Since(start) > time.Second {
+				t.Errorf("retry() took %v", time.Since(start))
+			}
+		})
+	}
+}
@@ -20,48 +20,72 @@ import (
 	"context"
 	"errors"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/deadletter"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
 	"log"
 	"reflect"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type ManagedKafkaConsumer struct {
-	cancel  context.CancelFunc
-	wg      *sync.WaitGroup
-	config  configuration.Config
-	output  func(msg model.ConsumerMessage) error
-	mux     sync.Mutex
-	onError func(topic string, err error)
-	stopped bool
-	topics  []string
+	cancel      context.CancelFunc
+	wg          *sync.WaitGroup
+	config      configuration.Config
+	output      func(msg model.ConsumerMessage) error
+	mux         sync.Mutex //guards the state; never held while waiting for a consumer to stop, to keep Ready responsive
+	restartMux  sync.Mutex //serializes starts and stops of the consumer
+	onError     func(topic string, err error)
+	stopped     bool
+	topics      []string                        //topics of the running consumer
+	requested   []string                        //topics of the latest UpdateTopics call
+	active      atomic.Pointer[map[string]bool] //set of requested topics; messages of other topics are ignored
+	deadLetters *deadletter.Writer              //nil if no dead_letter_topic is configured
 }
 
 func NewManagedKafkaConsumer(config configuration.Config, onError func(topic string, err error)) *ManagedKafkaConsumer {
-	return &ManagedKafkaConsumer{
+	result := &ManagedKafkaConsumer{
 		config:  config,
 		onError: onError,
 	}
+	if config.DeadLetterTopic != "" && config.DeadLetterTopic != "-" {
+		result.deadLetters = deadletter.NewWriter(config.KafkaUrl, config.DeadLetterTopic)
+	}
+	return result
 }
 
 func (this *ManagedKafkaConsumer) Stop() {
+	this.restartMux.Lock()
+	defer this.restartMux.Unlock()
 	this.mux.Lock()
-	defer this.mux.Unlock()
-	this.stop()
 	this.stopped = true
+	this.mux.Unlock()
+	this.stop()
+	if this.deadLetters != nil {
+		err := this.deadLetters.Close()
+		if err != nil {
+			log.Println("WARNING: unable to close dead-letter writer", err)
+		}
+	}
 	return
 }
 
+// stop cancels the running consumer and waits until its workers are done
+// must be called while holding restartMux and without holding mux
 func (this *ManagedKafkaConsumer) stop() {
-	if this.cancel != nil {
+	this.mux.Lock()
+	cancel, wg := this.cancel, this.wg
+	this.cancel, this.wg = nil, nil
+	this.mux.Unlock()
+	if cancel != nil {
 		log.Println("stop consumer")
-		this.cancel()
-		this.cancel = nil
+		cancel()
 	}
-	if this.wg != nil {
-		this.wg.Wait()
-		this.wg = nil
+	if wg != nil {
+		wg.Wait()
 	}
 	return
 }
@@ -70,9 +94,42 @@ func (this *ManagedKafkaConsumer) SetOutputCallback(callback func(msg model.Cons
 	this.output = callback
 }
 
-func (this *ManagedKafkaConsumer) UpdateTopics(topics []string) (err error) {
+// Ready returns an error if the consumer is not running
+func (this *ManagedKafkaConsumer) Ready() error {
 	this.mux.Lock()
 	defer this.mux.Unlock()
+	if this.stopped {
+		return errors.New("consumer is stopped")
+	}
+	if len(this.requested) > 0 && this.cancel == nil {
+		return errors.New("consumer is not running")
+	}
+	return nil
+}
+
+// Restart replaces the running consumer with a new one for the latest requested topics
+func (this *ManagedKafkaConsumer) Restart() (err error) {
+	this.mux.Lock()
+	if this.stopped {
+		this.mux.Unlock()
+		return nil
+	}
+	topics := this.requested
+	this.topics = nil
+	this.mux.Unlock()
+	return this.UpdateTopics(topics)
+}
+
+func (this *ManagedKafkaConsumer) UpdateTopics(topics []string) (err error) {
+	this.restartMux.Lock()
+	defer this.restartMux.Unlock()
+	this.mux.Lock()
+	this.requested = topics
+	active := map[string]bool{}
+	for _, topic := range topics {
+		active[topic] = true
+	}
+	this.active.Store(&active)
 	sort.Strings(this.topics)
 	sort.Strings(topics)
 	if len(topics) <= 20 {
@@ -81,29 +138,73 @@ func (this *ManagedKafkaConsumer) UpdateTopics(topics []string) (err error) {
 		log.Println("update consumer topics: ", len(topics))
 	}
 	if !this.stopped && reflect.DeepEqual(this.topics, topics) {
+		this.mux.Unlock()
 		log.Println("no topic changes -> continue with current consumer")
 		return nil
 	}
-	defer func() {
-		if err == nil {
-			this.topics = topics
-		} else {
-			this.topics = nil
-		}
-	}()
+	if !this.stopped && this.topics != nil && containsAll(this.topics, topics) {
+		this.mux.Unlock()
+		//a restart would trigger a rebalance of the consumer group
+		log.Println("topics removed -> continue with current consumer and ignore removed topics")
+		return nil
+	}
+	this.topics = nil
+	this.mux.Unlock()
+
 	this.stop()
-	var ctx context.Context
-	ctx, this.cancel = context.WithCancel(context.Background())
-	this.wg = &sync.WaitGroup{}
-	return StartKafkaLastOffsetConsumerGroup(ctx, this.wg, this.config.KafkaUrl, this.config.KafkaConsumerGroup, topics, func(msg model.ConsumerMessage) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	err = StartKafkaLastOffsetConsumerGroup(ctx, wg, this.config.KafkaUrl, this.config.KafkaConsumerGroup, topics, this.config.KafkaConsumerWorkers, func(msg model.ConsumerMessage) error {
+		if active := this.active.Load(); active != nil && !(*active)[msg.Topic] {
+			return nil
+		}
 		if !this.stopped && this.output != nil {
 			err := this.output(msg)
 			if errors.Is(err, model.ErrWillBeIgnored) {
 				log.Println("WARNING: kafka listener has thrown an error but will not be retried", err)
+				this.publishDeadLetter(ctx, msg, err)
 				return nil
 			}
 			return err
 		}
 		return nil
-	}, this.onError)
+	}, this.onError, func(msg model.ConsumerMessage, err error) {
+		this.publishDeadLetter(ctx, msg, err)
+	})
+
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if err != nil {
+		cancel()
+		return err
+	}
+	this.topics = topics
+	this.cancel = cancel
+	this.wg = wg
+	return nil
+}
+
+// publishDeadLetter records a message, which could not be handled, in the dead-letter topic (if configured)
+// only the publish is retried; the message is not handled again
+func (this *ManagedKafkaConsumer) publishDeadLetter(ctx context.Context, msg model.ConsumerMessage, reason error) {
+	if this.deadLetters == nil {
+		return
+	}
+	err := retry(ctx, func() error {
+		return this.deadLetters.Publish(msg, reason)
+	}, func(n int64) time.Duration {
+		return time.Duration(n) * time.Second
+	}, time.Minute)
+	if err != nil {
+		log.Println("ERROR: unable to publish dead letter; message is lost", msg.Topic, err)
+	}
+}
+
+func containsAll(list []string, elements []string) bool {
+	for _, element := range elements {
+		if !slices.Contains(list, element) {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* Count of received mqtt messages, which may wait for processing*/
+const mqttQueueSize = 100
+
+const mqttTimeout = 10 * time.Second
+
+// ManagedMqttSubscriber subscribes to the event topics (config.MqttTopicPrefix + topic) of a mqtt broker,
+// for deployments without kafka (e.g. at the edge).
+// the subscriber does not read the device topics of the platform mqtt connector (event/<local device id>/<local service id>
+// with protocol payloads). it expects the events in the format of the kafka device topics:
+//   - topic: config.MqttTopicPrefix + model.ServiceIdToTopic(<service id>), e.g. "events/urn_infai_ses_service_123"
+//   - payload: json of model.EventMessage, i.e. {"device_id": <device id>, "service_id": <service id>, "value": <marshalled service output>}
+//
+// so a bridge has to republish the kafka device events (or equally converted events) to the broker.
+// kafka itself is not needed with this source, if the register is reloaded periodically instead of by kafka cache invalidations
+// (see configuration.Config.EventSource); replays and dead letters are only supported with kafka.
+// mqtt messages have no timestamp; events are processed with the time of their arrival.
+// messages are processed in order of arrival and are acknowledged as soon as they are queued;
+// queued messages are lost if the service stops.
+// topics are subscribed and unsubscribed individually, updates do not interrupt the other subscriptions.
+type ManagedMqttSubscriber struct {
+	config  configuration.Config
+	client  paho.Client
+	output  func(msg model.ConsumerMessage) error
+	onError func(topic string, err error)
+	mux     sync.Mutex
+	stopped bool
+	topics  []string
+	queue   chan model.ConsumerMessage
+	done    chan struct{}
+}
+
+func NewManagedMqttSubscriber(config configuration.Config, onError func(topic string, err error)) (*ManagedMqttSubscriber, error) {
+	result := &ManagedMqttSubscriber{
+		config:  config,
+		onError: onError,
+		queue:   make(chan model.ConsumerMessage, mqttQueueSize),
+		done:    make(chan struct{}),
+	}
+	options := paho.NewClientOptions().
+		AddBroker(config.MqttBroker).
+		SetClientID(config.MqttClientId).
+		SetUsername(config.MqttUser).
+		SetPassword(config.MqttPw).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetOrderMatters(true).
+		SetOnConnectHandler(func(_ paho.Client) {
+			err := result.Restart()
+			if err != nil {
+				log.Println("ERROR: unable to subscribe after mqtt (re)connect", err)
+				result.onError("", err)
+			}
+		}).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			log.Println("WARNING: lost mqtt connection", err)
+		})
+	result.client = paho.NewClient(options)
+	go result.process()
+	token := result.client.Connect()
+	if !token.WaitTimeout(mqttTimeout) {
+		result.Stop()
+		return nil, errors.New("timeout while connecting to mqtt broker")
+	}
+	if token.Error() != nil {
+		result.Stop()
+		return nil, fmt.Errorf("unable to connect to mqtt broker: %w", token.Error())
+	}
+	return result, nil
+}
+
+func (this *ManagedMqttSubscriber) SetOutputCallback(callback func(msg model.ConsumerMessage) error) {
+	this.output = callback
+}
+
+func (this *ManagedMqttSubscriber) UpdateTopics(topics []string) (err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.stopped {
+		return errors.New("subscriber is stopped")
+	}
+	if len(topics) <= 20 {
+		log.Println("update mqtt topics: ", topics)
+	} else {
+		log.Println("update mqtt topics: ", len(topics))
+	}
+	if !this.client.IsConnected() {
+		this.topics = topics //subscribed by the on-connect handler
+		return nil
+	}
+	removed := slices.DeleteFunc(slices.Clone(this.topics), func(topic string) bool {
+		return slices.Contains(topics, topic)
+	})
+	added := slices.DeleteFunc(slices.Clone(topics), func(topic string) bool {
+		return slices.Contains(this.topics, topic)
+	})
+	err = this.unsubscribe(removed)
+	if err != nil {
+		return err
+	}
+	err = this.subscribe(added)
+	if err != nil {
+		return err
+	}
+	this.topics = topics
+	return nil
+}
+
+// Restart subscribes to all requested topics; used after (re)connects, which lose the subscriptions of the clean session
+func (this *ManagedMqttSubscriber) Restart() error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.stopped {
+		return nil
+	}
+	return this.subscribe(this.topics)
+}
+
+func (this *ManagedMqttSubscriber) Ready() error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.stopped {
+		return errors.New("subscriber is stopped")
+	}
+	if !this.client.IsConnectionOpen() {
+		return errors.New("not connected to mqtt broker")
+	}
+	return nil
+}
+
+func (this *ManagedMqttSubscriber) Stop() {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.stopped {
+		return
+	}
+	this.stopped = true
+	close(this.done)
+	this.client.Disconnect(250)
+}
+
+func (this *ManagedMqttSubscriber) subscribe(topics []string) error {
+	if len(topics) == 0 {
+		return nil
+	}
+	filters := map[string]byte{}
+	for _, topic := range topics {
+		filters[this.config.MqttTopicPrefix+topic] = this.config.MqttQos
+	}
+	return waitForToken(this.client.SubscribeMultiple(filters, this.receive))
+}
+
+func (this *ManagedMqttSubscriber) unsubscribe(topics []string) error {
+	if len(topics) == 0 {
+		return nil
+	}
+	filters := []string{}
+	for _, topic := range topics {
+		filters = append(filters, this.config.MqttTopicPrefix+topic)
+	}
+	return waitForToken(this.client.Unsubscribe(filters...))
+}
+
+func (this *ManagedMqttSubscriber) receive(_ paho.Client, msg paho.Message) {
+	select {
+	case <-this.done:
+	case this.queue <- model.ConsumerMessage{
+		Topic:   strings.TrimPrefix(msg.Topic(), this.config.MqttTopicPrefix),
+		Message: msg.Payload(),
+	}:
+	}
+}
+
+func (this *ManagedMqttSubscriber) process() {
+	for {
+		select {
+		case <-this.done:
+			return
+		case msg := <-this.queue:
+			if this.output == nil {
+				continue
+			}
+			err := retry(context.Background(), func() error {
+				err := this.output(msg)
+				if errors.Is(err, model.ErrWillBeIgnored) {
+					log.Println("WARNING: mqtt listener has thrown an error but will not be retried", err)
+					return nil
+				}
+				return err
+			}, func(n int64) time.Duration {
+				return time.Duration(n) * time.Second
+			}, 10*time.Minute)
+			if err != nil {
+				log.Println("ERROR: unable to handle mqtt message", err)
+				this.onError(msg.Topic, err)
+			}
+		}
+	}
+}
+
+func waitForToken(token paho.Token) error {
+	if !token.WaitTimeout(mqttTimeout) {
+		return errors.New("timeout while waiting for mqtt broker")
+	}
+	return token.Error()
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"github.com/segmentio/kafka-go"
+	"hash/fnv"
+	"strconv"
+	"sync"
+)
+
+// workerPool processes messages in parallel;
+// messages with the same ordering key are always handled by the same worker and therefore in order
+type workerPool struct {
+	queues []chan kafka.Message
+	wg     sync.WaitGroup
+}
+
+func newWorkerPool(workers int, queueSize int, process func(msg kafka.Message)) *workerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	result := &workerPool{}
+	for i := 0; i < workers; i++ {
+		queue := make(chan kafka.Message, queueSize)
+		result.queues = append(result.queues, queue)
+		result.wg.Add(1)
+		go func() {
+			defer result.wg.Done()
+			for msg := range queue {
+				process(msg)
+			}
+		}()
+	}
+	return result
+}
+
+// Add blocks while the queue of the responsible worker is full
+func (this *workerPool) Add(msg kafka.Message) {
+	this.queues[this.workerIndex(orderingKey(msg))] <- msg
+}
+
+// Close waits until all added messages are processed
+func (this *workerPool) Close() {
+	for _, queue := range this.queues {
+		close(queue)
+	}
+	this.wg.Wait()
+}
+
+func (this *workerPool) workerIndex(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(this.queues)))
+}
+
+// orderingKey returns the key of messages, which must be processed in order:
+// the topic (one topic per service) with the message key (device id),
+// or with the partition if the message has no key
+func orderingKey(msg kafka.Message) string {
+	if len(msg.Key) == 0 {
+		return msg.Topic + "#" + strconv.Itoa(msg.Partition)
+	}
+	return msg.Topic + "/" + string(msg.Key)
+}
+
+type partition struct {
+	topic     string
+	partition int
+}
+
+type pendingMessage struct {
+	msg  kafka.Message
+	done bool
+}
+
+// offsetTracker remembers the fetched but not yet committed messages of each partition.
+// a message is only committed, when it and all earlier messages of its partition are done.
+type offsetTracker struct {
+	mux       sync.Mutex
+	pending   map[partition][]*pendingMessage
+	commitMux sync.Mutex
+	committed map[partition]int64
+	commit    func(msg kafka.Message) error
+}
+
+func newOffsetTracker(commit func(msg kafka.Message) error) *offsetTracker {
+	return &offsetTracker{
+		pending:   map[partition][]*pendingMessage{},
+		committed: map[partition]int64{},
+		commit:    commit,
+	}
+}
+
+// Add must be called in fetch order, before the message is handed to a worker
+func (this *offsetTracker) Add(msg kafka.Message) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	key := partition{topic: msg.Topic, partition: msg.Partition}
+	this.pending[key] = append(this.pending[key], &pendingMessage{msg: msg})
+}
+
+// Done marks the message as processed and commits the newest message of the partition,
+// which has no unfinished predecessors
+func (this *offsetTracker) Done(msg kafka.Message) error {
+	key := partition{topic: msg.Topic, partition: msg.Partition}
+	var commit *kafka.Message
+	this.mux.Lock()
+	pending := this.pending[key]
+	for _, p := range pending {
+		if p.msg.Offset == msg.Offset {
+			p.done = true
+			break
+		}
+	}
+	i := 0
+	for ; i < len(pending) && pending[i].done; i++ {
+		commit = &pending[i].msg
+	}
+	if i == len(pending) {
+		delete(this.pending, key)
+	} else {
+		this.pending[key] = pending[i:]
+	}
+	this.mux.Unlock()
+
+	if commit == nil {
+		return nil
+	}
+	this.commitMux.Lock()
+	defer this.commitMux.Unlock()
+	if committed, ok := this.committed[key]; ok && committed >= commit.Offset {
+		return nil //a newer offset has already been committed by another worker
+	}
+	err := this.commit(*commit)
+	if err != nil {
+		return err
+	}
+	this.committed[key] = commit.Offset
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"github.com/segmentio/kafka-go"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	committed := []int64{}
+	tracker := newOffsetTracker(func(msg kafka.Message) error {
+		committed = append(committed, msg.Offset)
+		return nil
+	})
+	messages := []kafka.Message{}
+	for i := int64(0); i < 5; i++ {
+		msg := kafka.Message{Topic: "t", Partition: 0, Offset: i}
+		messages = append(messages, msg)
+		tracker.Add(msg)
+	}
+	other := kafka.Message{Topic: "t", Partition: 1, Offset: 42}
+	tracker.Add(other)
+
+	for _, i := range []int{1, 2} {
+		err := tracker.Done(messages[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(committed) != 0 {
+		t.Fatalf("committed before earlier messages are done: %v", committed)
+	}
+	err := tracker.Done(messages[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Done(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Done(messages[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracker.Done(messages[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(committed, []int64{2, 42, 4}) {
+		t.Errorf("unexpected commits %v", committed)
+	}
+	if len(tracker.pending) != 0 {
+		t.Errorf("unexpected pending messages %#v", tracker.pending)
+	}
+}
+
+func TestWorkerPoolOrder(t *testing.T) {
+	mux := sync.Mutex{}
+	received := map[string][]int64{}
+	pool := newWorkerPool(4, 2, func(msg kafka.Message) {
+		time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+		mux.Lock()
+		defer mux.Unlock()
+		received[string(msg.Key)] = append(received[string(msg.Key)], msg.Offset)
+	})
+	for i := int64(0); i < 1000; i++ {
+		pool.Add(kafka.Message{Topic: "t", Key: []byte("device" + strconv.Itoa(int(i%7))), Offset: i})
+	}
+	pool.Close()
+	for key, offsets := range received {
+		for i := 1; i < len(offsets); i++ {
+			if offsets[i-1] >= offsets[i] {
+				t.Errorf("%v: messages out of order %v", key, offsets)
+				break
+			}
+		}
+	}
+	if len(received) != 7 {
+		t.Errorf("unexpected keys %v", len(received))
+	}
+}
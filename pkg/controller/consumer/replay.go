@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"github.com/segmentio/kafka-go"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+var ErrReplayIncomplete = errors.New("replay incomplete")
+
+// ReplayKafkaConsumerGroup consumes the topics from the first events at or after from
+// up to the last events which existed at the start of the replay.
+// the offsets of the consumer group are reset to from, before consuming;
+// the group must not be the group of the live consumer.
+// messages are passed to the listener in order; listener errors stop the replay without committing the failed message.
+// if no event is received for idleTimeout before all partitions are replayed, ErrReplayIncomplete is returned.
+func ReplayKafkaConsumerGroup(ctx context.Context, broker string, groupId string, topics []string, from time.Time, idleTimeout time.Duration, listener func(msg model.ConsumerMessage) error) (events int, err error) {
+	if len(topics) == 0 {
+		return 0, nil
+	}
+	pending, err := resetConsumerGroupOffsets(ctx, broker, groupId, topics, from)
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		StartOffset:    kafka.FirstOffset,
+		CommitInterval: time.Second,
+		Brokers:        []string{broker},
+		GroupID:        groupId,
+		GroupTopics:    topics,
+		Logger:         log.New(io.Discard, "", 0),
+		ErrorLogger:    log.New(os.Stdout, "[KAFKA-ERROR] ", log.Default().Flags()),
+	})
+	defer r.Close()
+
+	for len(pending) > 0 {
+		fetchCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+		m, err := r.FetchMessage(fetchCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return events, fmt.Errorf("%w: idle timeout with %v unfinished partitions", ErrReplayIncomplete, len(pending))
+		}
+		if err != nil {
+			return events, err
+		}
+		key := partition{topic: m.Topic, partition: m.Partition}
+		end, ok := pending[key]
+		if !ok || m.Offset >= end {
+			continue //event was produced after the start of the replay
+		}
+		err = listener(model.ConsumerMessage{
+			Topic:     m.Topic,
+			Key:       string(m.Key),
+			Message:   m.Value,
+			Timestamp: m.Time.Unix(),
+		})
+		if err != nil {
+			return events, err
+		}
+		events++
+		err = r.CommitMessages(ctx, m)
+		if err != nil {
+			return events, err
+		}
+		if m.Offset >= end-1 {
+			delete(pending, key)
+		}
+	}
+	return events, nil
+}
+
+// resetConsumerGroupOffsets commits the offsets of the first events at or after from for all partitions of the topics
+// returns the end offsets (exclusive) of the partitions which have events to replay
+func resetConsumerGroupOffsets(ctx context.Context, broker string, groupId string, topics []string, from time.Time) (pending map[partition]int64, err error) {
+	client := &kafka.Client{Addr: kafka.TCP(broker)}
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read topic metadata: %w", err)
+	}
+	requests := map[string][]kafka.OffsetRequest{}
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			log.Println("WARNING: skip topic", topic.Name, topic.Error)
+			continue
+		}
+		for _, p := range topic.Partitions {
+			requests[topic.Name] = append(requests[topic.Name], kafka.TimeOffsetOf(p.ID, from), kafka.LastOffsetOf(p.ID))
+		}
+	}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: requests})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list offsets: %w", err)
+	}
+
+	pending = map[partition]int64{}
+	commits := map[string][]kafka.OffsetCommit{}
+	for topic, partitions := range offsets.Topics {
+		for _, p := range partitions {
+			if p.Error != nil {
+				return nil, fmt.Errorf("unable to list offsets of %v %v: %w", topic, p.Partition, p.Error)
+			}
+			start := p.LastOffset
+			for offset := range p.Offsets {
+				if offset >= 0 {
+					start = offset
+				}
+			}
+			commits[topic] = append(commits[topic], kafka.OffsetCommit{Partition: p.Partition, Offset: start})
+			if start < p.LastOffset {
+				pending[partition{topic: topic, partition: p.Partition}] = p.LastOffset
+			}
+		}
+	}
+	resp, err := client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      groupId,
+		GenerationID: -1,
+		Topics:       commits,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to reset offsets of consumer group %v: %w", groupId, err)
+	}
+	for topic, partitions := range resp.Topics {
+		for _, p := range partitions {
+			if p.Error != nil {
+				return nil, fmt.Errorf("unable to reset offset of %v %v for consumer group %v: %w", topic, p.Partition, groupId, p.Error)
+			}
+		}
+	}
+	return pending, nil
+}
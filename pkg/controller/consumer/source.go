@@ -0,0 +1,53 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+)
+
+const (
+	KafkaSource = "kafka"
+	MqttSource  = "mqtt"
+)
+
+// Source delivers the events of the subscribed topics (one topic per service, see model.ServiceIdToTopic) to the output callback
+// errors, which can not be recovered by retries, are passed to the onError function of the source
+type Source interface {
+	SetOutputCallback(callback func(msg model.ConsumerMessage) error)
+	UpdateTopics(topics []string) error
+	Restart() error //resubscribes to the latest requested topics
+	Ready() error   //returns an error if the source is not able to receive events
+	Stop()
+}
+
+var _ Source = &ManagedKafkaConsumer{}
+var _ Source = &ManagedMqttSubscriber{}
+
+// NewSource creates the source selected by config.EventSource; defaults to kafka
+func NewSource(config configuration.Config, onError func(topic string, err error)) (Source, error) {
+	switch config.EventSource {
+	case "", KafkaSource:
+		return NewManagedKafkaConsumer(config, onError), nil
+	case MqttSource:
+		return NewManagedMqttSubscriber(config, onError)
+	default:
+		return nil, fmt.Errorf("unknown event_source %#v; expected %#v or %#v", config.EventSource, KafkaSource, MqttSource)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consumer
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"testing"
+)
+
+func TestNewSource(t *testing.T) {
+	source, err := NewSource(configuration.Config{}, func(topic string, err error) {})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := source.(*ManagedKafkaConsumer); !ok {
+		t.Errorf("expected kafka consumer as default source, got %T", source)
+	}
+	_, err = NewSource(configuration.Config{EventSource: "amqp"}, func(topic string, err error) {})
+	if err == nil {
+		t.Error("expected error for unknown event source")
+	}
+}
@@ -18,10 +18,13 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/consumer"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/metrics"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
 	"github.com/SENERGY-Platform/converter/lib/converter"
 	devicerepo "github.com/SENERGY-Platform/device-repository/lib/client"
@@ -35,34 +38,192 @@ import (
 	"github.com/SENERGY-Platform/service-commons/pkg/signal"
 	"github.com/valkey-io/valkey-go"
 	"log"
+	"net/http"
 	"slices"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Controller struct {
+	ctx              context.Context
 	config           configuration.Config
 	mux              sync.RWMutex
-	handler          []HandlerInfo
+	reloadMux        sync.Mutex
+	routes           RoutingIndex
+	handlers         []*HandlerInfo //handlers of the current routes; replaced, never modified
 	selectionClient  client.Client
 	deviceRepoClient devicerepo.Interface
 	anomalyStore     *anomalystore.Mongo
 	valKeyClient     valkey.Client
 	marshaller       *marshaller.Marshaller
 	debounce         *Debounce
-	consumer         *consumer.ManagedKafkaConsumer
+	signals          *signal.Broker
+	consumer         consumer.Source
+	silentDevices    *SilentDeviceWatcher
+	supervisor       *Supervisor
+	metrics          *metrics.Metrics
+	restartPending   atomic.Bool //consumer restart is scheduled
+	reloadPending    atomic.Bool //register reload retry is scheduled
+	registerLoaded   atomic.Bool //LoadRegister has succeeded at least once
+	suppressNotify   bool        //no notifications are sent, e.g. while replaying events
 }
 
 func StartController(ctx context.Context, wg *sync.WaitGroup, config configuration.Config, register *handler.Register) (controller *Controller, err error) {
+	controller, err = newController(ctx, wg, config)
+	if err != nil {
+		return controller, err
+	}
+
+	controller.consumer, err = consumer.NewSource(config, func(topic string, err error) {
+		controller.scheduleConsumerRestart(controller.supervisor.Failure(ConsumerComponent, fmt.Errorf("error while consuming topic %s: %w", topic, err)))
+	})
+	if err != nil {
+		log.Println("ERROR: unable to create event source", err)
+		return controller, err
+	}
+
+	controller.consumer.SetOutputCallback(controller.HandleConsumerMessage)
+
+	if config.SilentDeviceCheckInterval != "" && config.SilentDeviceCheckInterval != "-" {
+		var timeout time.Duration //0: only devices with own timeout attribute are watched
+		if config.SilentDeviceTimeout != "" && config.SilentDeviceTimeout != "-" {
+			timeout, err = time.ParseDuration(config.SilentDeviceTimeout)
+			if err != nil {
+				log.Println("ERROR: unable to parse silent_device_timeout", err)
+				return controller, err
+			}
+		}
+		interval, err := time.ParseDuration(config.SilentDeviceCheckInterval)
+		if err != nil {
+			log.Println("ERROR: unable to parse silent_device_check_interval", err)
+			return controller, err
+		}
+		controller.silentDevices = NewSilentDeviceWatcher(timeout, interval, controller.valKeyClient, HandlerInfo{
+			config:           config,
+			valKeyClient:     controller.valKeyClient,
+			deviceRepoClient: controller.deviceRepoClient,
+			anomalyStore:     controller.anomalyStore,
+			metrics:          controller.metrics,
+		})
+	}
+
+	for _, h := range register.List() {
+		log.Println("start with known handler", h.Name, h.Mode)
+	}
+
+	s := controller.signals
+
+	serviceIDs, err := controller.LoadRegister(register)
+	if err != nil {
+		log.Println("ERROR: unable to LoadRegister", err)
+		return controller, err
+	}
+
+	err = controller.updateConsumer(serviceIDs)
+	if err != nil {
+		log.Println("ERROR: unable to updateConsumer", err)
+		return controller, err
+	}
+
+	if controller.silentDevices != nil {
+		controller.silentDevices.Start(ctx, wg)
+	}
+
+	//changes of single devices only update the routes of the device; all other changes reload the whole register
+	deviceTopic := ""
+	if config.DeviceKafkaTopic != "" && config.DeviceKafkaTopic != "-" {
+		deviceTopic = config.DeviceKafkaTopic
+	}
+	reloadTopics := slices.DeleteFunc(slices.Clone(config.CacheInvalidationKafkaTopics), func(topic string) bool {
+		return topic == deviceTopic
+	})
+	if len(reloadTopics) > 0 {
+		err = invalidator.StartCacheInvalidatorAll(ctx, kafka.Config{
+			KafkaUrl:    config.KafkaUrl,
+			StartOffset: kafka.LastOffset,
+			Wg:          wg,
+		}, reloadTopics, s)
+		if err != nil {
+			log.Println("ERROR: unable to StartCacheInvalidatorAll", err)
+			return nil, err
+		}
+	}
+	if deviceTopic != "" {
+		err = invalidator.StartCacheInvalidator(ctx, kafka.Config{
+			KafkaUrl:    config.KafkaUrl,
+			StartOffset: kafka.LastOffset,
+			Wg:          wg,
+		}, []string{deviceTopic}, invalidator.GetKnownSignalMapper(invalidator.KnownTopics{DeviceTopic: deviceTopic}), s)
+		if err != nil {
+			log.Println("ERROR: unable to StartCacheInvalidator for devices", err)
+			return nil, err
+		}
+	}
+
+	//without kafka (e.g. with the mqtt event source), changes are only noticed by periodic reloads
+	if config.RegisterReloadInterval != "" && config.RegisterReloadInterval != "-" {
+		interval, err := time.ParseDuration(config.RegisterReloadInterval)
+		if err != nil {
+			log.Println("ERROR: unable to parse register_reload_interval", err)
+			return nil, err
+		}
+		startPeriodicInvalidation(ctx, wg, interval, s)
+	}
+
+	s.Sub("reload", signal.Known.CacheInvalidationAll, func(value string, wg *sync.WaitGroup) {
+		controller.debounce.Do(func() {
+			controller.reload(register)
+		})
+	})
+	s.Sub("device-update", signal.Known.DeviceCacheInvalidation, func(deviceId string, wg *sync.WaitGroup) {
+		controller.updateDevice(register, deviceId)
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		s.Unsub("reload")
+		s.Unsub("device-update")
+		controller.consumer.Stop()
+		controller.anomalyStore.Disconnect()
+	}()
+
+	return controller, nil
+}
+
+// startPeriodicInvalidation signals the invalidation of all caches in the interval, which reloads the register like a cache invalidation by kafka
+func startPeriodicInvalidation(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, s *signal.Broker) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Pub(signal.Known.CacheInvalidationAll, "")
+			}
+		}
+	}()
+}
+
+// newController creates the clients used by the controller, without consuming events
+func newController(ctx context.Context, wg *sync.WaitGroup, config configuration.Config) (controller *Controller, err error) {
 	selectionClient := client.NewClient(config.DeviceSelectionUrl)
 	repoClient := devicerepo.NewClient(config.DeviceRepositoryUrl, nil)
 
+	collector := metrics.New()
+
 	valkeyClient, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{config.ValKeyUrl}})
 	if err != nil {
 		log.Println("ERROR: unable to create valkey client", err)
 		return controller, err
 	}
+	valkeyClient = collector.WrapValkey(valkeyClient)
 
 	conv, err := converter.New()
 	if err != nil {
@@ -79,92 +240,191 @@ func StartController(ctx context.Context, wg *sync.WaitGroup, config configurati
 
 	m := marshaller.New(marshallerconfig.Config{}, conv, conceptrepo)
 
-	anomalyStore, err := anomalystore.New(config)
+	anomalyStore, err := anomalystore.New(config, collector.MongoMonitor())
 	if err != nil {
 		log.Println("ERROR: unable to create anomalystore", err)
 		return controller, err
 	}
 
+	failureBudgetWindow, err := time.ParseDuration(config.FailureBudgetWindow)
+	if err != nil {
+		log.Println("ERROR: unable to parse failure_budget_window", err)
+		return controller, err
+	}
+
 	controller = &Controller{
+		ctx:              ctx,
 		config:           config,
 		mux:              sync.RWMutex{},
-		handler:          nil,
+		routes:           RoutingIndex{},
 		selectionClient:  selectionClient,
 		deviceRepoClient: repoClient,
 		valKeyClient:     valkeyClient,
 		anomalyStore:     anomalyStore,
 		marshaller:       m,
 		debounce:         &Debounce{Duration: 2 * time.Second}, //to prevent to many reloads if a series of changes happens
-		consumer: consumer.NewManagedKafkaConsumer(config, func(topic string, err error) {
-			log.Fatalf("FATAL: error while consuming topic %s: %v\n", topic, err)
-		}),
+		signals:          s,
+		supervisor:       NewSupervisor(config.FailureBudget, failureBudgetWindow),
+		metrics:          collector,
 	}
+	return controller, nil
+}
 
-	controller.consumer.SetOutputCallback(controller.HandleConsumerMessage)
+// Send passes the event to the matching handlers
+// handlers, which fail with model.ErrWillBeIgnored, do not stop the other handlers; they are reported in a model.HandlerError
+func (this *Controller) Send(msg model.EventMessageWithTimestamp) (err error) {
+	if this.silentDevices != nil {
+		err = this.silentDevices.Seen(msg.DeviceId, msg.ServiceId)
+		if err != nil {
+			return errors.Join(fmt.Errorf("unable to update silent device deadline: %w", err), model.ErrWithRetry)
+		}
+	}
+	return this.send(msg, nil)
+}
 
-	for _, h := range register.List() {
-		log.Println("start with known handler", h.Name)
+// send passes the event to the matching handlers; if handlers is not nil, only to the listed handlers
+func (this *Controller) send(msg model.EventMessageWithTimestamp, handlers map[string]bool) error {
+	this.mux.RLock()
+	routes := this.routes.Get(msg.DeviceId, msg.ServiceId)
+	this.mux.RUnlock()
+	var failed *model.HandlerError
+	for _, route := range routes {
+		name := route.Handler.handler.Name
+		if handlers != nil && !handlers[name] {
+			continue
+		}
+		this.metrics.EventsMatched.WithLabelValues(name).Inc()
+		err := route.Handler.do(msg.DeviceId, route.Service, msg.Value, msg.Timestamp)
+		if errors.Is(err, model.ErrWillBeIgnored) {
+			if failed == nil {
+				failed = &model.HandlerError{}
+			}
+			failed.Handlers = append(failed.Handlers, name)
+			failed.Err = errors.Join(failed.Err, fmt.Errorf("%v: %w", name, err))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if failed != nil {
+		return failed
 	}
+	return nil
+}
 
-	serviceIDs, err := controller.LoadRegister(register)
+const (
+	ConsumerComponent = "event consumer"
+	RegisterComponent = "register reload"
+)
+
+// reload refreshes the register and the consumed topics
+// on errors the last good register stays in use and the reload is retried with backoff
+func (this *Controller) reload(register *handler.Register) {
+	serviceIDs, err := this.LoadRegister(register)
 	if err != nil {
-		log.Println("ERROR: unable to LoadRegister", err)
-		return controller, err
+		this.scheduleReload(register, this.supervisor.Failure(RegisterComponent, err))
+		return
 	}
-
-	err = controller.updateConsumer(serviceIDs)
+	this.supervisor.Recovered(RegisterComponent)
+	err = this.updateConsumer(serviceIDs)
 	if err != nil {
-		log.Println("ERROR: unable to updateConsumer", err)
-		return controller, err
+		this.scheduleConsumerRestart(this.supervisor.Failure(ConsumerComponent, fmt.Errorf("unable to update kafka consumer: %w", err)))
 	}
+}
 
-	err = invalidator.StartCacheInvalidatorAll(ctx, kafka.Config{
-		KafkaUrl:    config.KafkaUrl,
-		StartOffset: kafka.LastOffset,
-		Wg:          wg,
-	}, config.CacheInvalidationKafkaTopics, s)
+// updateDevice refreshes the routes of the device and the consumed topics
+// falls back to a full reload, if the device could not be updated
+func (this *Controller) updateDevice(register *handler.Register, deviceId string) {
+	serviceIDs, err := this.UpdateDevice(deviceId)
 	if err != nil {
-		log.Println("ERROR: unable to StartCacheInvalidatorAll", err)
-		return nil, err
+		log.Println("WARNING: unable to update device", deviceId, "-> reload register", err)
+		this.debounce.Do(func() {
+			this.reload(register)
+		})
+		return
 	}
+	err = this.updateConsumer(serviceIDs)
+	if err != nil {
+		this.scheduleConsumerRestart(this.supervisor.Failure(ConsumerComponent, fmt.Errorf("unable to update kafka consumer: %w", err)))
+	}
+}
 
-	s.Sub("reload", signal.Known.CacheInvalidationAll, func(value string, wg *sync.WaitGroup) {
-		controller.debounce.Do(func() {
-			serviceIDs, err = controller.LoadRegister(register)
-			if err != nil {
-				log.Fatalln("ERROR: unable to refresh register", err)
-				return
-			}
-			err = controller.updateConsumer(serviceIDs)
-			if err != nil {
-				log.Fatalln("FATAL: unable to update kafka consumer", err)
-				return
-			}
-		})
-	})
+func (this *Controller) scheduleReload(register *handler.Register, backoff time.Duration) {
+	if !this.reloadPending.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		select {
+		case <-this.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		this.reloadPending.Store(false)
+		this.reload(register)
+	}()
+}
 
-	wg.Add(1)
+func (this *Controller) scheduleConsumerRestart(backoff time.Duration) {
+	if !this.restartPending.CompareAndSwap(false, true) {
+		return
+	}
 	go func() {
-		defer wg.Done()
-		<-ctx.Done()
-		s.Unsub("reload")
-		controller.consumer.Stop()
-		controller.anomalyStore.Disconnect()
+		select {
+		case <-this.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		this.restartPending.Store(false)
+		err := this.consumer.Restart()
+		if err != nil {
+			this.scheduleConsumerRestart(this.supervisor.Failure(ConsumerComponent, fmt.Errorf("unable to restart kafka consumer: %w", err)))
+			return
+		}
+		this.supervisor.Recovered(ConsumerComponent)
 	}()
+}
 
-	return controller, nil
+// MetricsHandler serves the prometheus metrics of the service
+func (this *Controller) MetricsHandler() http.Handler {
+	return this.metrics.Handler()
 }
 
-func (this *Controller) Send(msg model.EventMessageWithTimestamp) (err error) {
-	this.mux.RLock()
-	defer this.mux.RUnlock()
-	for _, entry := range this.handler {
-		err = entry.Send(msg)
+// Readiness checks valkey, mongodb, the event consumer (kafka or mqtt) and if the register has been loaded
+func (this *Controller) Readiness() model.Readiness {
+	checks := map[string]error{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	checks["valkey"] = this.valKeyClient.Do(ctx, this.valKeyClient.B().Ping().Build()).Error()
+	checks["mongo"] = this.anomalyStore.Ping()
+
+	checks["consumer"] = this.consumer.Ready()
+	if failure, ok := this.supervisor.Status().Failures[ConsumerComponent]; ok && checks["consumer"] == nil {
+		checks["consumer"] = errors.New(failure)
+	}
+
+	if !this.registerLoaded.Load() {
+		checks["register"] = errors.New("register has not been loaded")
+	} else {
+		checks["register"] = nil
+	}
+
+	result := model.Readiness{Ready: true, Checks: map[string]string{}}
+	for name, err := range checks {
 		if err != nil {
-			return err
+			result.Ready = false
+			result.Checks[name] = err.Error()
+		} else {
+			result.Checks[name] = "ok"
 		}
 	}
-	return nil
+	return result
+}
+
+// Status returns the degraded state of the service
+func (this *Controller) Status() model.ServiceStatus {
+	return this.supervisor.Status()
 }
 
 // InternalAdminToken is expired and invalid. but because this service does not validate the received tokens,
@@ -173,12 +433,12 @@ func (this *Controller) Send(msg model.EventMessageWithTimestamp) (err error) {
 const InternalAdminToken = devicerepo.InternalAdminToken
 
 func (this *Controller) LoadRegister(register *handler.Register) (serviceIds []string, err error) {
-	this.mux.Lock()
-	defer this.mux.Unlock()
+	this.reloadMux.Lock()
+	defer this.reloadMux.Unlock()
 	if register == nil {
 		register = handler.Registry
 	}
-	this.handler = []HandlerInfo{}
+	handlers := []*HandlerInfo{}
 
 	protocols := map[string]models.Protocol{}
 	protocolList, err, _ := this.deviceRepoClient.ListProtocols(InternalAdminToken, 9999, 0, "name.asc")
@@ -191,6 +451,9 @@ func (this *Controller) LoadRegister(register *handler.Register) (serviceIds []s
 	}
 
 	for _, h := range register.List() {
+		if h.Mode == handler.ModeDisabled {
+			continue
+		}
 		selectables, _, err := this.selectionClient.GetSelectables(InternalAdminToken, []models.DeviceGroupFilterCriteria{
 			{
 				Interaction: models.EVENT,
@@ -213,35 +476,93 @@ func (this *Controller) LoadRegister(register *handler.Register) (serviceIds []s
 			log.Printf("DEBUG: found %v selectables\n", len(selectables))
 		}
 		match := []deviceselectionmodel.Selectable{}
+		deviceParameters := map[string]handler.Parameters{}
 		for _, selectable := range selectables {
-			if selectable.Device != nil && this.hasAnomalyDetectorAttribute(selectable.Device) {
-				for _, s := range selectable.Services {
-					if !slices.Contains(serviceIds, s.Id) {
-						serviceIds = append(serviceIds, s.Id)
-					}
+			if selectable.Device == nil {
+				continue
+			}
+			settings := ParseDeviceSettings(this.config.AnomalyDetectorAttribute, selectable.Device.Attributes)
+			if settings.IsHandlerEnabled(h) {
+				parameters, changed, err := settings.HandlerParameters(h)
+				if err != nil {
+					log.Printf("WARNING: ignore invalid %v parameters of device %v: %v\n", h.Name, selectable.Device.Id, err)
+				}
+				if changed {
+					deviceParameters[selectable.Device.Id] = parameters
 				}
 				match = append(match, selectable)
 			}
 		}
 		if this.config.Debug {
-			log.Printf("DEBUG: found %v matches and %v sevices\n", len(match), len(serviceIds))
+			log.Printf("DEBUG: found %v matches\n", len(match))
 		}
 		entry, err := this.createRouterEntry(h, match, protocols)
 		if err != nil {
 			return nil, err
 		}
-		this.handler = append(this.handler, entry)
+		entry.deviceParameters = deviceParameters
+		handlers = append(handlers, entry)
 	}
+	return this.applyHandlers(handlers)
+}
+
+// applyHandlers replaces the current routes with routes to the handlers
+// and returns the services which have to be consumed
+func (this *Controller) applyHandlers(handlers []*HandlerInfo) (serviceIds []string, err error) {
+	deviceServices := map[string][]string{}
+	silentTimeouts := map[string]time.Duration{}
+	for _, h := range handlers {
+		for _, selectable := range h.match {
+			if selectable.Device == nil {
+				continue
+			}
+			if _, done := silentTimeouts[selectable.Device.Id]; this.silentDevices != nil && !done {
+				settings := ParseDeviceSettings(this.config.AnomalyDetectorAttribute, selectable.Device.Attributes)
+				if timeout, ok := this.silentDevices.DeviceTimeout(selectable.Device.Id, settings); ok {
+					silentTimeouts[selectable.Device.Id] = timeout
+				}
+			}
+			for _, s := range selectable.Services {
+				if !slices.Contains(serviceIds, s.Id) {
+					serviceIds = append(serviceIds, s.Id)
+				}
+				if !slices.Contains(deviceServices[selectable.Device.Id], s.Id) {
+					deviceServices[selectable.Device.Id] = append(deviceServices[selectable.Device.Id], s.Id)
+				}
+			}
+		}
+	}
+	routes := NewRoutingIndex(handlers)
+	if this.silentDevices != nil {
+		err = this.silentDevices.UpdateTracked(deviceServices, silentTimeouts)
+		if err != nil {
+			log.Println("ERROR: unable to update tracked silent devices", err)
+			return nil, err
+		}
+	}
+	this.mux.Lock()
+	this.routes = routes
+	this.handlers = handlers
+	this.mux.Unlock()
+	this.registerLoaded.Store(true)
+
+	services := 0
+	for _, serviceIds := range deviceServices {
+		services = services + len(serviceIds)
+	}
+	this.metrics.RegisterDevices.Set(float64(len(deviceServices)))
+	this.metrics.RegisterServices.Set(float64(services))
+	this.metrics.RegisterTopics.Set(float64(len(serviceIds)))
 	return serviceIds, nil
 }
 
-func (this *Controller) createRouterEntry(h handler.Entry, match []deviceselectionmodel.Selectable, protocols map[string]models.Protocol) (HandlerInfo, error) {
+func (this *Controller) createRouterEntry(h handler.Entry, match []deviceselectionmodel.Selectable, protocols map[string]models.Protocol) (*HandlerInfo, error) {
 	aspectNode, err, _ := this.deviceRepoClient.GetAspectNode(h.Aspect)
 	if err != nil {
 		log.Println("ERROR: unable to GetAspectNode", err)
-		return HandlerInfo{}, err
+		return nil, err
 	}
-	return HandlerInfo{
+	return &HandlerInfo{
 		config:           this.config,
 		handler:          h,
 		match:            match,
@@ -251,17 +572,8 @@ func (this *Controller) createRouterEntry(h handler.Entry, match []deviceselecti
 		valKeyClient:     this.valKeyClient,
 		deviceRepoClient: this.deviceRepoClient,
 		anomalyStore:     this.anomalyStore,
+		metrics:          this.metrics,
+		deviceTypes:      &deviceTypeServices{},
+		suppressNotify:   this.suppressNotify,
 	}, nil
 }
-
-func (this *Controller) hasAnomalyDetectorAttribute(device *deviceselectionmodel.PermSearchDevice) bool {
-	if device == nil {
-		return false
-	}
-	for _, attr := range device.Attributes {
-		if attr.Key == this.config.AnomalyDetectorAttribute && strings.ToLower(strings.TrimSpace(attr.Value)) == "true" {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/service-commons/pkg/signal"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartPeriodicInvalidation(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &signal.Broker{}
+	count := atomic.Int64{}
+	s.Sub("test", signal.Known.CacheInvalidationAll, func(value string, wg *sync.WaitGroup) {
+		count.Add(1)
+	})
+	startPeriodicInvalidation(ctx, wg, 50*time.Millisecond, s)
+	time.Sleep(275 * time.Millisecond)
+	cancel()
+	wg.Wait()
+	time.Sleep(20 * time.Millisecond) //subscribers are called asynchronously
+	if count.Load() < 3 || count.Load() > 6 {
+		t.Errorf("unexpected count of invalidations %v", count.Load())
+	}
+	stopped := count.Load()
+	time.Sleep(100 * time.Millisecond)
+	if count.Load() != stopped {
+		t.Error("invalidations after stop")
+	}
+}
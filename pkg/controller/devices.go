@@ -0,0 +1,124 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	devicerepo "github.com/SENERGY-Platform/device-repository/lib/client"
+	devicerepomodel "github.com/SENERGY-Platform/device-repository/lib/model"
+	deviceselectionmodel "github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// deviceTypeServices caches the services of each device type which match a handler
+// the cache is filled on the first device update after a full reload
+type deviceTypeServices struct {
+	services map[string][]models.Service //device type id -> matching services; nil if not loaded
+}
+
+// UpdateDevice refreshes the routes of a single changed or deleted device
+// the routes of all other devices are kept; handler infos are copied instead of modified, to not disturb running events
+func (this *Controller) UpdateDevice(deviceId string) (serviceIds []string, err error) {
+	this.reloadMux.Lock()
+	defer this.reloadMux.Unlock()
+	if !this.registerLoaded.Load() {
+		return nil, errors.New("register has not been loaded")
+	}
+	device, err, code := this.deviceRepoClient.ReadDevice(deviceId, InternalAdminToken, devicerepo.READ)
+	deleted := code == http.StatusNotFound
+	if err != nil && !deleted {
+		return nil, fmt.Errorf("unable to read device %v: %w", deviceId, err)
+	}
+	settings := DeviceSettings{}
+	if !deleted {
+		settings = ParseDeviceSettings(this.config.AnomalyDetectorAttribute, device.Attributes)
+	}
+
+	this.mux.RLock()
+	current := this.handlers
+	this.mux.RUnlock()
+
+	handlers := []*HandlerInfo{}
+	for _, h := range current {
+		updated := h.withoutDevice(deviceId)
+		if settings.IsHandlerEnabled(updated.handler) {
+			services, err := updated.servicesOfDeviceType(device.DeviceTypeId)
+			if err != nil {
+				return nil, err
+			}
+			if len(services) > 0 {
+				parameters, changed, err := settings.HandlerParameters(updated.handler)
+				if err != nil {
+					log.Printf("WARNING: ignore invalid %v parameters of device %v: %v\n", updated.handler.Name, deviceId, err)
+				}
+				if changed {
+					updated.deviceParameters[deviceId] = parameters
+				}
+				updated.match = append(updated.match, deviceselectionmodel.Selectable{
+					Device:   &deviceselectionmodel.PermSearchDevice{Device: device, DisplayName: device.Name},
+					Services: services,
+				})
+			}
+		}
+		handlers = append(handlers, updated)
+	}
+	if this.config.Debug {
+		log.Printf("DEBUG: updated routes of device %v (deleted=%v)\n", deviceId, deleted)
+	}
+	return this.applyHandlers(handlers)
+}
+
+// withoutDevice returns a copy of the handler info without matches and parameters of the device
+func (this *HandlerInfo) withoutDevice(deviceId string) *HandlerInfo {
+	result := *this
+	result.match = slices.DeleteFunc(slices.Clone(this.match), func(selectable deviceselectionmodel.Selectable) bool {
+		return selectable.Device != nil && selectable.Device.Id == deviceId
+	})
+	result.deviceParameters = maps.Clone(this.deviceParameters)
+	if result.deviceParameters == nil {
+		result.deviceParameters = map[string]handler.Parameters{}
+	}
+	delete(result.deviceParameters, deviceId)
+	return &result
+}
+
+// servicesOfDeviceType returns the event services of the device type which match the function and aspect of the handler
+// must only be called while holding the reloadMux of the controller
+func (this *HandlerInfo) servicesOfDeviceType(deviceTypeId string) ([]models.Service, error) {
+	if this.deviceTypes.services == nil {
+		selectables, err, _ := this.deviceRepoClient.GetDeviceTypeSelectablesV2([]devicerepomodel.FilterCriteria{{
+			Interaction: models.EVENT,
+			FunctionId:  this.handler.Function,
+			AspectId:    this.handler.Aspect,
+		}}, "", false, false)
+		if err != nil {
+			return nil, fmt.Errorf("unable to GetDeviceTypeSelectablesV2: %w", err)
+		}
+		services := map[string][]models.Service{}
+		for _, selectable := range selectables {
+			services[selectable.DeviceTypeId] = selectable.Services
+		}
+		this.deviceTypes.services = services
+	}
+	return this.deviceTypes.services[deviceTypeId], nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/metrics"
+	devicerepo "github.com/SENERGY-Platform/device-repository/lib/client"
+	devicerepomodel "github.com/SENERGY-Platform/device-repository/lib/model"
+	deviceselectionmodel "github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+type updateDeviceRepo struct {
+	devicerepo.Interface
+	devices            map[string]models.Device
+	deviceTypeRequests int
+}
+
+func (this *updateDeviceRepo) ReadDevice(id string, token string, action devicerepomodel.AuthAction) (result models.Device, err error, errCode int) {
+	device, ok := this.devices[id]
+	if !ok {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	return device, nil, http.StatusOK
+}
+
+func (this *updateDeviceRepo) GetDeviceTypeSelectablesV2(query []devicerepomodel.FilterCriteria, pathPrefix string, includeModified bool, servicesMustMatchAllCriteria bool) (result []devicerepomodel.DeviceTypeSelectable, err error, code int) {
+	this.deviceTypeRequests++
+	return []devicerepomodel.DeviceTypeSelectable{{DeviceTypeId: "dt1", Services: []models.Service{{Id: "s1"}}}}, nil, http.StatusOK
+}
+
+func TestUpdateDevice(t *testing.T) {
+	device := func(id string) *deviceselectionmodel.PermSearchDevice {
+		result := &deviceselectionmodel.PermSearchDevice{}
+		result.Id = id
+		return result
+	}
+	repo := &updateDeviceRepo{devices: map[string]models.Device{}}
+	existing := &HandlerInfo{
+		handler: handler.Entry{Name: "h1", Type: "t1"},
+		match: []deviceselectionmodel.Selectable{
+			{Device: device("d1"), Services: []models.Service{{Id: "s1"}}},
+			{Device: device("d2"), Services: []models.Service{{Id: "s1"}}},
+		},
+		deviceParameters: map[string]handler.Parameters{"d2": {"sigma": 3.0}},
+		deviceRepoClient: repo,
+		deviceTypes:      &deviceTypeServices{},
+	}
+	ctrl := &Controller{
+		config:           configuration.Config{AnomalyDetectorAttribute: "anomaly-detector"},
+		deviceRepoClient: repo,
+		metrics:          metrics.New(),
+		handlers:         []*HandlerInfo{existing},
+	}
+	ctrl.registerLoaded.Store(true)
+
+	//new device is added
+	repo.devices["d3"] = models.Device{Id: "d3", DeviceTypeId: "dt1", Attributes: []models.Attribute{{Key: "anomaly-detector", Value: "true"}}}
+	serviceIds, err := ctrl.UpdateDevice("d3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !slices.Equal(serviceIds, []string{"s1"}) {
+		t.Errorf("unexpected service ids %#v", serviceIds)
+	}
+	if routes := ctrl.routes.Get("d3", "s1"); len(routes) != 1 {
+		t.Errorf("expected route for d3/s1, got %#v", routes)
+	}
+	if routes := ctrl.routes.Get("d1", "s1"); len(routes) != 1 {
+		t.Errorf("expected route for d1/s1 to be kept, got %#v", routes)
+	}
+	if len(existing.match) != 2 {
+		t.Errorf("previous handler info has been modified: %#v", existing.match)
+	}
+
+	//handler is disabled for device
+	repo.devices["d2"] = models.Device{Id: "d2", DeviceTypeId: "dt1", Attributes: []models.Attribute{{Key: "anomaly-detector", Value: "true"}, {Key: "anomaly-detector:disabled", Value: "t1"}}}
+	_, err = ctrl.UpdateDevice("d2")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if routes := ctrl.routes.Get("d2", "s1"); len(routes) != 0 {
+		t.Errorf("unexpected route for d2/s1: %#v", routes)
+	}
+	if _, ok := ctrl.handlers[0].deviceParameters["d2"]; ok {
+		t.Error("parameters of d2 have not been removed")
+	}
+
+	//device is deleted
+	serviceIds, err = ctrl.UpdateDevice("d1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if routes := ctrl.routes.Get("d1", "s1"); len(routes) != 0 {
+		t.Errorf("unexpected route for deleted d1: %#v", routes)
+	}
+	if routes := ctrl.routes.Get("d3", "s1"); len(routes) != 1 {
+		t.Errorf("expected route for d3/s1 to be kept, got %#v", routes)
+	}
+	if !slices.Equal(serviceIds, []string{"s1"}) {
+		t.Errorf("unexpected service ids %#v", serviceIds)
+	}
+	if repo.deviceTypeRequests != 1 {
+		t.Errorf("expected device type services to be cached, got %v requests", repo.deviceTypeRequests)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/metrics"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
 	"github.com/SENERGY-Platform/device-repository/lib/client"
 	deviceselectionmodel "github.com/SENERGY-Platform/device-selection/pkg/model"
@@ -34,67 +35,82 @@ type HandlerInfo struct {
 	config           configuration.Config
 	handler          handler.Entry
 	match            []deviceselectionmodel.Selectable
+	deviceParameters map[string]handler.Parameters //device id -> parameters with device overrides; devices without overrides use handler.Parameters
 	protocols        map[string]models.Protocol
 	aspectNode       models.AspectNode
 	marshaller       *marshaller.Marshaller
 	valKeyClient     valkey.Client
 	deviceRepoClient client.Interface
 	anomalyStore     *anomalystore.Mongo
-}
-
-func (this *HandlerInfo) Send(msg model.EventMessageWithTimestamp) error {
-	for _, e := range this.match {
-		if e.Device != nil && e.Device.Id == msg.DeviceId {
-			for _, s := range e.Services {
-				if s.Id == msg.ServiceId {
-					return this.do(msg.DeviceId, s, msg.Value, msg.Timestamp)
-				}
-			}
-			return nil
-		}
-	}
-	return nil
+	metrics          *metrics.Metrics
+	deviceTypes      *deviceTypeServices //shared by all copies of the handler info
+	suppressNotify   bool
 }
 
 func (this *HandlerInfo) do(deviceId string, service models.Service, rawValue map[string]interface{}, timestamp int64) error {
 	marshalledValue, err := this.marshal(rawValue, service)
 	if err != nil {
+		this.metrics.MarshallingFailures.WithLabelValues(this.handler.Name).Inc()
 		return errors.Join(fmt.Errorf("unable to marshal"), err, model.ErrWillBeIgnored)
 	}
-	list, err := this.storeAndListValues(this.handler.Name, deviceId, service.Id, this.handler.BufferSize, marshalledValue)
-	if err != nil {
-		return fmt.Errorf("unable to storeAndListValues: %w", err)
+	var list []interface{}
+	var timestamps []int64
+	if this.handler.BufferWindow > 0 {
+		list, timestamps, err = this.storeAndListWindow(this.handler.Name, deviceId, service.Id, this.handler.BufferWindow, marshalledValue, timestamp)
+		if err != nil {
+			return fmt.Errorf("unable to storeAndListWindow: %w", err)
+		}
+	} else {
+		list, timestamps, err = this.storeAndListValues(this.handler.Name, deviceId, service.Id, this.handler.BufferSize, marshalledValue, timestamp)
+		if err != nil {
+			return fmt.Errorf("unable to storeAndListValues: %w", err)
+		}
 	}
 	if len(list) < this.handler.BufferSize {
 		return nil
 	}
-	anomaly, desc, err := this.callHandler(Context{
-		DeviceId:  deviceId,
-		ServiceId: service.Id,
-		Store:     &Store{ValKeyClient: this.valKeyClient},
+	parameters, ok := this.deviceParameters[deviceId]
+	if !ok {
+		parameters = this.handler.Parameters
+	}
+	result, err := this.callHandler(Context{
+		DeviceId:   deviceId,
+		ServiceId:  service.Id,
+		Timestamp:  timestamp,
+		Timestamps: timestamps,
+		Store:      &Store{ValKeyClient: this.valKeyClient},
+		Parameters: parameters,
 	}, list)
 	if err != nil {
 		return errors.Join(fmt.Errorf("unable to handle"), err, model.ErrWillBeIgnored)
 	}
-	if anomaly {
-		err = this.reactToAnomaly(this.handler.Name, deviceId, service.Id, desc, timestamp)
+	if result.Anomaly {
+		err = this.reactToAnomaly(this.handler.Name, deviceId, service.Id, result, timestamp)
 		if err != nil {
 			return errors.Join(fmt.Errorf("unable to react to anomaly"), err, model.ErrWillBeIgnored)
 		}
+	} else if this.handler.AutoResolve {
+		err = this.reactToRecovery(this.handler.Name, deviceId, service.Id, timestamp)
+		if err != nil {
+			return errors.Join(fmt.Errorf("unable to react to recovery"), err, model.ErrWillBeIgnored)
+		}
 	}
 	return nil
 }
 
 type Context = handler.Context
 
-func (this *HandlerInfo) callHandler(context Context, values []interface{}) (anomaly bool, description string, err error) {
+func (this *HandlerInfo) callHandler(context Context, values []interface{}) (result handler.Result, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			anomaly = false
-			description = ""
+			this.metrics.HandlerPanics.WithLabelValues(this.handler.Name).Inc()
+			result = handler.Result{}
 			err = errors.New("panic:" + fmt.Sprint(r))
 		}
 	}()
-	anomaly, description, err = this.handler.Handle(context, values)
-	return anomaly, description, err
+	result, err = this.handler.Evaluate(context, values)
+	if err != nil {
+		this.metrics.HandlerErrors.WithLabelValues(this.handler.Name).Inc()
+	}
+	return result, err
 }
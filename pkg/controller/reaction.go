@@ -22,7 +22,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
 	devicerepo "github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/valkey-io/valkey-go"
 	"io"
 	"log"
 	"net/http"
@@ -30,12 +32,69 @@ import (
 	"time"
 )
 
-func (this *HandlerInfo) reactToAnomaly(handlerName string, deviceId string, serviceId string, desc string, timestamp int64) (err error) {
-	err = errors.Join(err, this.notify(handlerName, deviceId, serviceId, desc))
-	err = errors.Join(err, this.storeAnomalyState(handlerName, deviceId, serviceId, desc, timestamp))
+func (this *HandlerInfo) reactToAnomaly(handlerName string, deviceId string, serviceId string, result handler.Result, timestamp int64) (err error) {
+	this.metrics.AnomaliesDetected.WithLabelValues(handlerName, string(result.Severity)).Inc()
+	if this.notifyEnabled() {
+		this.logNotificationError(this.notify(handlerName, deviceId, serviceId, result))
+	}
+	err = errors.Join(err, this.storeAnomalyState(handlerName, deviceId, serviceId, result, timestamp))
+	if this.handler.AutoResolve {
+		err = errors.Join(err, this.markOpenAnomaly(handlerName, deviceId, serviceId))
+	}
 	return err
 }
 
+// reactToRecovery resolves the open anomaly of the handler/device/service
+// a marker in valkey is used to skip the anomaly-store if no anomaly has been detected since the last recovery
+// the marker is only removed after the anomaly has been resolved, so that a retry of a failed resolve finds it again
+func (this *HandlerInfo) reactToRecovery(handlerName string, deviceId string, serviceId string, timestamp int64) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := openAnomalyKey(handlerName, deviceId, serviceId)
+	err = this.valKeyClient.Do(ctx, this.valKeyClient.B().Get().Key(key).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resolved, err := this.anomalyStore.ResolveAnomaly(handlerName, deviceId, serviceId, timestamp, this.handler.Mode == handler.ModeShadow)
+	if err != nil {
+		return err
+	}
+	err = this.valKeyClient.Do(ctx, this.valKeyClient.B().Del().Key(key).Build()).Error()
+	if err != nil {
+		return err
+	}
+	if resolved && this.config.NotifyOnResolve && this.notifyEnabled() {
+		this.logNotificationError(this.notifyResolved(handlerName, deviceId, serviceId))
+	}
+	return nil
+}
+
+// logNotificationError logs failed notifications (counted in metrics.NotificationFailures) instead of failing the event:
+// the anomaly is stored, and a repeated processing of the event would update the handler state twice
+func (this *HandlerInfo) logNotificationError(err error) {
+	if err != nil {
+		log.Println("ERROR: unable to send notification", err)
+	}
+}
+
+// notifyEnabled is false for handlers in shadow mode and if notifications are suppressed (e.g. during a replay)
+func (this *HandlerInfo) notifyEnabled() bool {
+	return !this.suppressNotify && this.handler.Mode != handler.ModeShadow
+}
+
+func (this *HandlerInfo) markOpenAnomaly(handlerName string, deviceId string, serviceId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return this.valKeyClient.Do(ctx, this.valKeyClient.B().Set().Key(openAnomalyKey(handlerName, deviceId, serviceId)).Value("1").Build()).Error()
+}
+
+func openAnomalyKey(handlerName string, deviceId string, serviceId string) string {
+	return fmt.Sprintf("open_anomaly_%s_%s_%s", handlerName, deviceId, serviceId)
+}
+
 type Notification struct {
 	UserId  string `json:"userId" bson:"userId"`
 	Title   string `json:"title" bson:"title"`
@@ -43,17 +102,38 @@ type Notification struct {
 	Topic   string `json:"topic" bson:"topic"`
 }
 
-func (this *HandlerInfo) notify(handlerName string, deviceId string, serviceId string, desc string) error {
+func (this *HandlerInfo) notify(handlerName string, deviceId string, serviceId string, result handler.Result) error {
 	device, err, _ := this.deviceRepoClient.ReadExtendedDevice(deviceId, InternalAdminToken, devicerepo.READ, false)
 	if err != nil {
 		return fmt.Errorf("unable to get device id=%#v err=%w", deviceId, err)
 	}
-	msg := Notification{
+	return this.sendNotification(Notification{
 		UserId:  device.OwnerId,
-		Title:   "Anomaly Detected",
-		Message: fmt.Sprintf("%v anomaly detected for device %v (%v) in service %v\ndesc: %v\n", handlerName, device.DisplayName, device.Id, serviceId, desc),
+		Title:   fmt.Sprintf("Anomaly Detected (%v)", result.Severity),
+		Message: fmt.Sprintf("%v anomaly detected for device %v (%v) in service %v\ndesc: %v\nscore: %v\n", handlerName, device.DisplayName, device.Id, serviceId, result.Description, strconv.FormatFloat(result.Score, 'f', -1, 64)),
 		Topic:   this.config.NotificationTopic,
+	})
+}
+
+func (this *HandlerInfo) notifyResolved(handlerName string, deviceId string, serviceId string) error {
+	device, err, _ := this.deviceRepoClient.ReadExtendedDevice(deviceId, InternalAdminToken, devicerepo.READ, false)
+	if err != nil {
+		return fmt.Errorf("unable to get device id=%#v err=%w", deviceId, err)
 	}
+	return this.sendNotification(Notification{
+		UserId:  device.OwnerId,
+		Title:   "Anomaly Resolved",
+		Message: fmt.Sprintf("%v anomaly resolved for device %v (%v) in service %v\n", handlerName, device.DisplayName, device.Id, serviceId),
+		Topic:   this.config.NotificationTopic,
+	})
+}
+
+func (this *HandlerInfo) sendNotification(msg Notification) (err error) {
+	defer func() {
+		if err != nil {
+			this.metrics.NotificationFailures.Inc()
+		}
+	}()
 	b := new(bytes.Buffer)
 	err = json.NewEncoder(b).Encode(msg)
 	if err != nil {
@@ -82,6 +162,6 @@ func (this *HandlerInfo) notify(handlerName string, deviceId string, serviceId s
 	return nil
 }
 
-func (this *HandlerInfo) storeAnomalyState(handlerName string, deviceId string, serviceId string, desc string, timestamp int64) error {
-	return this.anomalyStore.StoreAnomaly(handlerName, deviceId, serviceId, desc, timestamp)
+func (this *HandlerInfo) storeAnomalyState(handlerName string, deviceId string, serviceId string, result handler.Result, timestamp int64) error {
+	return this.anomalyStore.StoreAnomaly(handlerName, deviceId, serviceId, result.Description, result.Score, result.Severity, timestamp, this.handler.Mode == handler.ModeShadow)
 }
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/metrics"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/tests/docker"
+	"github.com/valkey-io/valkey-go"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHandlerModes(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, valKeyIp, err := docker.ValKey(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, mongoIp, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://" + mongoIp + ":27017"
+
+	notifications := atomic.Int64{}
+	notifier := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		notifications.Add(1)
+	}))
+	defer notifier.Close()
+	config.NotificationUrl = notifier.URL
+	config.NotifyOnResolve = true
+
+	valkeyClient, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{valKeyIp + ":6379"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	store, err := anomalystore.New(config, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer store.Disconnect()
+
+	tests := []struct {
+		mode                  handler.Mode
+		expectedNotifications int64
+	}{
+		{mode: handler.ModeActive, expectedNotifications: 2},
+		{mode: handler.ModeShadow, expectedNotifications: 0},
+	}
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			notifications.Store(0)
+			name := "mode_" + string(test.mode)
+			info := HandlerInfo{
+				config:           config,
+				handler:          handler.Entry{Name: name, AutoResolve: true, Mode: test.mode},
+				valKeyClient:     valkeyClient,
+				deviceRepoClient: testDeviceRepo{},
+				anomalyStore:     store,
+				metrics:          metrics.New(),
+			}
+			err = info.reactToAnomaly(name, "d1", "s1", handler.Result{Anomaly: true, Description: "test", Score: 1, Severity: handler.SeverityWarning}, 10)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			err = info.reactToRecovery(name, "d1", "s1", 20)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if notifications.Load() != test.expectedNotifications {
+				t.Errorf("expected %v notifications, got %v", test.expectedNotifications, notifications.Load())
+			}
+			for _, shadow := range []bool{true, false} {
+				list, _, err := store.ListAnomalies(anomalystore.AnomalyQuery{Handler: name, Shadow: shadow})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				expected := 0
+				if shadow == (test.mode == handler.ModeShadow) {
+					expected = 1
+				}
+				if len(list) != expected {
+					t.Errorf("unexpected anomalies for shadow=%v: %#v", shadow, list)
+					continue
+				}
+				if expected == 1 && (list[0].Shadow != shadow || list[0].Status != anomalystore.StatusResolved) {
+					t.Errorf("unexpected anomaly %#v", list[0])
+				}
+			}
+		})
+	}
+
+	t.Run("mode switch", func(t *testing.T) {
+		notifications.Store(0)
+		name := "mode_switch"
+		shadowInfo := HandlerInfo{
+			config:           config,
+			handler:          handler.Entry{Name: name, AutoResolve: true, Mode: handler.ModeShadow},
+			valKeyClient:     valkeyClient,
+			deviceRepoClient: testDeviceRepo{},
+			anomalyStore:     store,
+			metrics:          metrics.New(),
+		}
+		err = shadowInfo.reactToAnomaly(name, "d1", "s1", handler.Result{Anomaly: true, Description: "test", Score: 1, Severity: handler.SeverityWarning}, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		activeInfo := shadowInfo
+		activeInfo.handler.Mode = handler.ModeActive
+		err = activeInfo.reactToRecovery(name, "d1", "s1", 20)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, _, err := store.ListAnomalies(anomalystore.AnomalyQuery{Handler: name, Shadow: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 1 || list[0].Status != anomalystore.StatusOpen {
+			t.Errorf("shadow anomaly should not be resolved by active handler %#v", list)
+		}
+		if notifications.Load() != 0 {
+			t.Errorf("unexpected notifications %v", notifications.Load())
+		}
+	})
+
+	t.Run("retry failed resolve", func(t *testing.T) {
+		name := "retry_resolve"
+		info := HandlerInfo{
+			config:           config,
+			handler:          handler.Entry{Name: name, AutoResolve: true, Mode: handler.ModeShadow},
+			valKeyClient:     valkeyClient,
+			deviceRepoClient: testDeviceRepo{},
+			anomalyStore:     store,
+			metrics:          metrics.New(),
+		}
+		err = info.reactToAnomaly(name, "d1", "s1", handler.Result{Anomaly: true, Description: "test", Score: 1, Severity: handler.SeverityWarning}, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		disconnected, err := anomalystore.New(config, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		disconnected.Disconnect()
+		failing := info
+		failing.anomalyStore = disconnected
+		err = failing.reactToRecovery(name, "d1", "s1", 20)
+		if err == nil {
+			t.Error("expected error of disconnected store")
+			return
+		}
+		err = info.reactToRecovery(name, "d1", "s1", 30)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, _, err := store.ListAnomalies(anomalystore.AnomalyQuery{Handler: name, Shadow: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 1 || list[0].Status != anomalystore.StatusResolved {
+			t.Errorf("unexpected anomalies %#v", list)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/consumer"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"log"
+	"sync"
+	"time"
+)
+
+type ReplayOptions struct {
+	From                  time.Time     //first event time to replay
+	Handlers              []string      //names of the handlers which receive the events; all disabled handlers if empty
+	ConsumerGroup         string        //must differ from config.KafkaConsumerGroup, to leave the offsets of the live consumer untouched
+	Mode                  handler.Mode  //mode of the replayed handlers (active or shadow); defaults to active
+	SuppressNotifications bool          //if true, no notifications are sent; the mode of the handlers is not changed
+	IdleTimeout           time.Duration //stop after this duration without new events
+}
+
+// Replay passes the events of the device topics since options.From to the selected handlers,
+// e.g. to build the state of a new handler from historical data.
+// events which are produced after the start of the replay are left to the live consumer.
+// the replay writes the value buffers, handler state and anomalies of the handlers, which the live service uses as well.
+// to not interleave historical and live events, the handlers must be disabled (handler.ModeDisabled) in the register
+// of the live service while they are replayed (see replayRegister); they may be enabled after the replay.
+// returns the count of replayed events
+func Replay(ctx context.Context, config configuration.Config, register *handler.Register, options ReplayOptions) (events int, err error) {
+	if options.ConsumerGroup == "" || options.ConsumerGroup == config.KafkaConsumerGroup {
+		return 0, errors.New("replay needs a consumer group, which differs from the live consumer group")
+	}
+	register, err = replayRegister(register, options)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+
+	controller, err := newController(ctx, wg, config)
+	if err != nil {
+		return 0, err
+	}
+	defer controller.anomalyStore.Disconnect()
+	defer controller.valKeyClient.Close()
+	controller.suppressNotify = options.SuppressNotifications
+
+	serviceIds, err := controller.LoadRegister(register)
+	if err != nil {
+		log.Println("ERROR: unable to LoadRegister", err)
+		return 0, err
+	}
+	topics := []string{}
+	for _, serviceId := range serviceIds {
+		topics = append(topics, model.ServiceIdToTopic(serviceId))
+	}
+	log.Printf("replay %v topics since %v\n", len(topics), options.From.Format(time.RFC3339))
+
+	return consumer.ReplayKafkaConsumerGroup(ctx, config.KafkaUrl, options.ConsumerGroup, topics, options.From, options.IdleTimeout, func(msg model.ConsumerMessage) error {
+		err := controller.HandleConsumerMessage(msg)
+		if errors.Is(err, model.ErrWillBeIgnored) {
+			log.Println("WARNING: skip event", msg.Topic, err)
+			return nil
+		}
+		return err
+	})
+}
+
+// replayRegister selects the handlers of the replay, which must be disabled in the given register,
+// and returns them in options.Mode (default active)
+func replayRegister(register *handler.Register, options ReplayOptions) (*handler.Register, error) {
+	names := options.Handlers
+	if len(names) == 0 {
+		for _, entry := range register.List() {
+			if entry.Mode == handler.ModeDisabled {
+				names = append(names, entry.Name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, errors.New("no disabled handlers to replay")
+		}
+	}
+	selected, err := register.Select(names...)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range selected.List() {
+		if entry.Mode != handler.ModeDisabled {
+			return nil, fmt.Errorf("handler %v is in %v mode; replayed handlers must be disabled in the live service, to not mix historical and live events", entry.Name, entry.Mode)
+		}
+	}
+	mode := options.Mode
+	if mode == "" {
+		mode = handler.ModeActive
+	}
+	if mode != handler.ModeActive && mode != handler.ModeShadow {
+		return nil, fmt.Errorf("invalid replay mode %v; expected active or shadow", mode)
+	}
+	return selected.WithMode(mode), nil
+}
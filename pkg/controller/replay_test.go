@@ -0,0 +1,108 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"reflect"
+	"testing"
+)
+
+func TestReplayRejectsLiveConsumerGroup(t *testing.T) {
+	config := configuration.Config{KafkaConsumerGroup: "live"}
+	for _, group := range []string{"", "live"} {
+		_, err := Replay(context.Background(), config, handler.NewRegister(), ReplayOptions{ConsumerGroup: group})
+		if err == nil {
+			t.Errorf("expected error for consumer group %#v", group)
+		}
+	}
+}
+
+func TestReplayRegister(t *testing.T) {
+	register := handler.NewRegister()
+	for name, mode := range map[string]handler.Mode{"live": handler.ModeActive, "new": handler.ModeDisabled, "other": handler.ModeDisabled} {
+		err := register.Register(name, "f", "a", "c", 2, handler.JumpBackHandler{}, handler.WithMode(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name     string
+		options  ReplayOptions
+		expected map[string]handler.Mode
+		wantErr  bool
+	}{
+		{
+			name:     "all disabled handlers",
+			options:  ReplayOptions{SuppressNotifications: true},
+			expected: map[string]handler.Mode{"new": handler.ModeActive, "other": handler.ModeActive},
+		},
+		{
+			name:     "shadow mode",
+			options:  ReplayOptions{Mode: handler.ModeShadow},
+			expected: map[string]handler.Mode{"new": handler.ModeShadow, "other": handler.ModeShadow},
+		},
+		{
+			name:    "disabled mode",
+			options: ReplayOptions{Mode: handler.ModeDisabled},
+			wantErr: true,
+		},
+		{
+			name:     "selected handler with notifications",
+			options:  ReplayOptions{Handlers: []string{"new"}},
+			expected: map[string]handler.Mode{"new": handler.ModeActive},
+		},
+		{
+			name:    "live handler",
+			options: ReplayOptions{Handlers: []string{"new", "live"}, SuppressNotifications: true},
+			wantErr: true,
+		},
+		{
+			name:    "unknown handler",
+			options: ReplayOptions{Handlers: []string{"unknown"}},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := replayRegister(register, test.options)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("replayRegister() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			actual := map[string]handler.Mode{}
+			for _, entry := range result.List() {
+				actual[entry.Name] = entry.Mode
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("replayRegister() got = %#v, want %#v", actual, test.expected)
+			}
+		})
+	}
+
+	t.Run("without disabled handlers", func(t *testing.T) {
+		live, _ := register.Select("live")
+		_, err := replayRegister(live, ReplayOptions{})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
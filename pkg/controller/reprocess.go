@@ -0,0 +1,74 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/deadletter"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"log"
+	"sync"
+)
+
+// Reprocess passes the events of the dead letters to the handlers of the register, which failed to process them,
+// e.g. after a fix of the marshalling of a service. events are not published again, so other consumers of the device topics
+// and handlers, which already processed the events, do not receive duplicates.
+// reprocessed events are handled like late events: they are added to the value buffers of the handlers as newest values.
+func Reprocess(ctx context.Context, config configuration.Config, register *handler.Register, options deadletter.ReinjectOptions) (reprocessed int, skipped int, failed int, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+
+	controller, err := newController(ctx, wg, config)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer controller.anomalyStore.Disconnect()
+	defer controller.valKeyClient.Close()
+
+	_, err = controller.LoadRegister(register)
+	if err != nil {
+		log.Println("ERROR: unable to LoadRegister", err)
+		return 0, 0, 0, err
+	}
+	return deadletter.Reinject(ctx, options, controller.HandleDeadLetter)
+}
+
+// HandleDeadLetter passes the event of the dead letter to the handlers, which failed to process it
+// or to all matching handlers, if the dead letter lists no handlers (e.g. the event could not be parsed)
+func (this *Controller) HandleDeadLetter(letter model.DeadLetter) error {
+	event, err := parseConsumerMessage(model.ConsumerMessage{
+		Topic:     letter.Topic,
+		Key:       letter.Key,
+		Message:   []byte(letter.Message),
+		Timestamp: letter.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+	var handlers map[string]bool
+	if len(letter.Handlers) > 0 {
+		handlers = map[string]bool{}
+		for _, name := range letter.Handlers {
+			handlers[name] = true
+		}
+	}
+	return this.send(event, handlers)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import "github.com/SENERGY-Platform/models/go/models"
+
+// RoutingIndex maps device id and service id to the handlers, which receive events of the service
+// the index is immutable after creation; reloads create a new index, which replaces the old one
+type RoutingIndex map[string]map[string][]Route
+
+type Route struct {
+	Handler *HandlerInfo
+	Service models.Service
+}
+
+func NewRoutingIndex(handlers []*HandlerInfo) RoutingIndex {
+	result := RoutingIndex{}
+	for _, h := range handlers {
+		for _, selectable := range h.match {
+			if selectable.Device == nil {
+				continue
+			}
+			services, ok := result[selectable.Device.Id]
+			if !ok {
+				services = map[string][]Route{}
+				result[selectable.Device.Id] = services
+			}
+			for _, service := range selectable.Services {
+				if containsRouteToHandler(services[service.Id], h) {
+					continue
+				}
+				services[service.Id] = append(services[service.Id], Route{Handler: h, Service: service})
+			}
+		}
+	}
+	return result
+}
+
+func containsRouteToHandler(routes []Route, h *HandlerInfo) bool {
+	for _, route := range routes {
+		if route.Handler == h {
+			return true
+		}
+	}
+	return false
+}
+
+func (this RoutingIndex) Get(deviceId string, serviceId string) []Route {
+	return this[deviceId][serviceId]
+}
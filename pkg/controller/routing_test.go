@@ -0,0 +1,59 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	deviceselectionmodel "github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"testing"
+)
+
+func TestRoutingIndex(t *testing.T) {
+	device := func(id string) *deviceselectionmodel.PermSearchDevice {
+		result := &deviceselectionmodel.PermSearchDevice{}
+		result.Id = id
+		return result
+	}
+	h1 := &HandlerInfo{match: []deviceselectionmodel.Selectable{
+		{Device: device("d1"), Services: []models.Service{{Id: "s1"}, {Id: "s2"}}},
+		{Device: device("d2"), Services: []models.Service{{Id: "s3"}}},
+		{Device: device("d1"), Services: []models.Service{{Id: "s1"}}},
+		{Services: []models.Service{{Id: "s4"}}},
+	}}
+	h2 := &HandlerInfo{match: []deviceselectionmodel.Selectable{
+		{Device: device("d1"), Services: []models.Service{{Id: "s1"}}},
+	}}
+	index := NewRoutingIndex([]*HandlerInfo{h1, h2})
+
+	routes := index.Get("d1", "s1")
+	if len(routes) != 2 || routes[0].Handler != h1 || routes[1].Handler != h2 || routes[0].Service.Id != "s1" {
+		t.Errorf("unexpected routes for d1/s1: %#v", routes)
+	}
+	routes = index.Get("d1", "s2")
+	if len(routes) != 1 || routes[0].Handler != h1 || routes[0].Service.Id != "s2" {
+		t.Errorf("unexpected routes for d1/s2: %#v", routes)
+	}
+	routes = index.Get("d2", "s3")
+	if len(routes) != 1 || routes[0].Handler != h1 {
+		t.Errorf("unexpected routes for d2/s3: %#v", routes)
+	}
+	for _, missing := range [][2]string{{"d2", "s1"}, {"d3", "s1"}, {"", "s4"}} {
+		if routes = index.Get(missing[0], missing[1]); len(routes) != 0 {
+			t.Errorf("unexpected routes for %v/%v: %#v", missing[0], missing[1], routes)
+		}
+	}
+}
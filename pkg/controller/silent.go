@@ -0,0 +1,230 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/valkey-io/valkey-go"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SilentDeviceHandlerName = "silent_device"
+
+const silentDeviceDeadlinesKey = "silent_device_deadlines" //sorted set; member = device/service, score = unix timestamp from which on the device/service is silent
+const silentDeviceFlaggedKey = "silent_device_flagged"     //set of device/service members with a reported silent_device anomaly
+const silentDeviceLockKey = "silent_device_check_lock"     //ensures that only one instance checks the deadlines per interval
+
+// SilentDeviceWatcher tracks when each matched device/service last sent an event
+// and reports a silent_device anomaly, if no event has been received within the timeout of the device.
+// the anomaly is resolved as soon as the device/service sends again.
+// the state is kept in valkey, to support multiple instances of the service.
+type SilentDeviceWatcher struct {
+	timeout      time.Duration //default timeout of devices without own timeout; 0 if only devices with own timeout are watched
+	interval     time.Duration
+	valKeyClient valkey.Client
+	reactor      HandlerInfo //used to react to silent_device anomalies like any other handler
+	mux          sync.RWMutex
+	tracked      map[string]time.Duration //device/service member -> timeout
+}
+
+// NewSilentDeviceWatcher creates a watcher with the default timeout for devices without own timeout (see DeviceTimeout)
+func NewSilentDeviceWatcher(timeout time.Duration, interval time.Duration, valKeyClient valkey.Client, reactor HandlerInfo) *SilentDeviceWatcher {
+	reactor.handler = handler.Entry{
+		Name:        SilentDeviceHandlerName,
+		AutoResolve: true,
+	}
+	return &SilentDeviceWatcher{
+		timeout:      timeout,
+		interval:     interval,
+		valKeyClient: valKeyClient,
+		reactor:      reactor,
+		tracked:      map[string]time.Duration{},
+	}
+}
+
+func (this *SilentDeviceWatcher) Start(ctx context.Context, wg *sync.WaitGroup) {
+	ticker := time.NewTicker(this.interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := this.Check()
+				if err != nil {
+					log.Println("ERROR: unable to check for silent devices", err)
+				}
+			}
+		}
+	}()
+}
+
+// DeviceTimeout returns the timeout of the device:
+// the duration of the device attribute <attr>:silent_device:timeout or the default timeout.
+// returns ok = false if the device is not watched (silent_device disabled for the device, or no timeout)
+func (this *SilentDeviceWatcher) DeviceTimeout(deviceId string, settings DeviceSettings) (timeout time.Duration, ok bool) {
+	if !settings.IsHandlerEnabled(handler.Entry{Name: SilentDeviceHandlerName}) {
+		return 0, false
+	}
+	timeout = this.timeout
+	if value, set := settings.Parameters[SilentDeviceHandlerName]["timeout"]; set {
+		deviceTimeout, err := time.ParseDuration(fmt.Sprint(value))
+		if err == nil && deviceTimeout > 0 {
+			timeout = deviceTimeout
+		} else {
+			log.Printf("WARNING: ignore invalid %v timeout %#v of device %v\n", SilentDeviceHandlerName, value, deviceId)
+		}
+	}
+	return timeout, timeout > 0
+}
+
+// UpdateTracked sets the device/services which are watched, with the timeouts of the devices (see DeviceTimeout)
+// devices without timeout are not watched
+// device/services without known events are expected to send within the timeout from now on
+func (this *SilentDeviceWatcher) UpdateTracked(deviceServices map[string][]string, timeouts map[string]time.Duration) error {
+	tracked := map[string]time.Duration{}
+	for deviceId, serviceIds := range deviceServices {
+		timeout, ok := timeouts[deviceId]
+		if !ok {
+			continue
+		}
+		for _, serviceId := range serviceIds {
+			tracked[silentDeviceMember(deviceId, serviceId)] = timeout
+		}
+	}
+	this.mux.Lock()
+	this.tracked = tracked
+	this.mux.Unlock()
+
+	if len(tracked) == 0 {
+		return nil
+	}
+	now := time.Now()
+	cmd := this.valKeyClient.B().Zadd().Key(silentDeviceDeadlinesKey).Nx().ScoreMember()
+	for member, timeout := range tracked {
+		cmd = cmd.ScoreMember(float64(now.Add(timeout).Unix()), member)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return this.valKeyClient.Do(ctx, cmd.Build()).Error()
+}
+
+// Seen marks the device/service as active, if it is tracked
+func (this *SilentDeviceWatcher) Seen(deviceId string, serviceId string) error {
+	member := silentDeviceMember(deviceId, serviceId)
+	this.mux.RLock()
+	timeout, tracked := this.tracked[member]
+	this.mux.RUnlock()
+	if !tracked {
+		return nil
+	}
+	deadline := float64(time.Now().Add(timeout).Unix())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return this.valKeyClient.Do(ctx, this.valKeyClient.B().Zadd().Key(silentDeviceDeadlinesKey).ScoreMember().ScoreMember(deadline, member).Build()).Error()
+}
+
+// Check reports silent device/services and resolves the anomalies of device/services which are active again
+// if another instance has checked within the current interval, Check does nothing
+func (this *SilentDeviceWatcher) Check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := this.valKeyClient.Do(ctx, this.valKeyClient.B().Set().Key(silentDeviceLockKey).Value("1").Nx().Px(this.interval/2).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return nil //locked by other instance
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	silent, err := this.valKeyClient.Do(ctx, this.valKeyClient.B().Zrangebyscore().Key(silentDeviceDeadlinesKey).Min("-inf").Max(strconv.FormatInt(now.Unix(), 10)).Withscores().Build()).AsZScores()
+	if err != nil {
+		return err
+	}
+	flaggedList, err := this.valKeyClient.Do(ctx, this.valKeyClient.B().Smembers().Key(silentDeviceFlaggedKey).Build()).AsStrSlice()
+	if err != nil {
+		return err
+	}
+	flagged := map[string]bool{}
+	for _, member := range flaggedList {
+		flagged[member] = true
+	}
+
+	this.mux.RLock()
+	tracked := this.tracked
+	this.mux.RUnlock()
+
+	silentMembers := map[string]bool{}
+	for _, entry := range silent {
+		silentMembers[entry.Member] = true
+		timeout, isTracked := tracked[entry.Member]
+		if !isTracked {
+			err = errors.Join(err, this.valKeyClient.Do(ctx, this.valKeyClient.B().Zrem().Key(silentDeviceDeadlinesKey).Member(entry.Member).Build()).Error())
+			continue
+		}
+		if flagged[entry.Member] {
+			continue
+		}
+		deviceId, serviceId := parseSilentDeviceMember(entry.Member)
+		lastSeen := time.Unix(int64(entry.Score), 0).Add(-timeout)
+		reactionErr := this.reactor.reactToAnomaly(SilentDeviceHandlerName, deviceId, serviceId, handler.Result{
+			Anomaly:     true,
+			Description: fmt.Sprintf("No event received since %v.", lastSeen.UTC().Format(time.RFC3339)),
+			Score:       now.Sub(lastSeen).Hours(),
+			Severity:    handler.SeverityWarning,
+		}, now.Unix())
+		if reactionErr != nil {
+			err = errors.Join(err, reactionErr)
+			continue
+		}
+		err = errors.Join(err, this.valKeyClient.Do(ctx, this.valKeyClient.B().Sadd().Key(silentDeviceFlaggedKey).Member(entry.Member).Build()).Error())
+	}
+
+	for member := range flagged {
+		if silentMembers[member] {
+			continue
+		}
+		deviceId, serviceId := parseSilentDeviceMember(member)
+		reactionErr := this.reactor.reactToRecovery(SilentDeviceHandlerName, deviceId, serviceId, now.Unix())
+		if reactionErr != nil {
+			err = errors.Join(err, reactionErr)
+			continue
+		}
+		err = errors.Join(err, this.valKeyClient.Do(ctx, this.valKeyClient.B().Srem().Key(silentDeviceFlaggedKey).Member(member).Build()).Error())
+	}
+	return err
+}
+
+func silentDeviceMember(deviceId string, serviceId string) string {
+	return deviceId + "/" + serviceId
+}
+
+func parseSilentDeviceMember(member string) (deviceId string, serviceId string) {
+	deviceId, serviceId, _ = strings.Cut(member, "/")
+	return deviceId, serviceId
+}
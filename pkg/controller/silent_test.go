@@ -0,0 +1,214 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/metrics"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/tests/docker"
+	devicerepo "github.com/SENERGY-Platform/device-repository/lib/client"
+	devicerepomodel "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/valkey-io/valkey-go"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSilentDeviceWatcher(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, valKeyIp, err := docker.ValKey(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, mongoIp, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://" + mongoIp + ":27017"
+
+	notificationsMux := sync.Mutex{}
+	notifications := []string{}
+	notifier := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		notificationsMux.Lock()
+		defer notificationsMux.Unlock()
+		temp, _ := io.ReadAll(request.Body)
+		notifications = append(notifications, string(temp))
+	}))
+	defer notifier.Close()
+	config.NotificationUrl = notifier.URL
+
+	valkeyClient, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{valKeyIp + ":6379"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	store, err := anomalystore.New(config, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer store.Disconnect()
+
+	interval := 100 * time.Millisecond
+	watcher := NewSilentDeviceWatcher(2*time.Second, interval, valkeyClient, HandlerInfo{
+		config:           config,
+		valKeyClient:     valkeyClient,
+		deviceRepoClient: testDeviceRepo{},
+		anomalyStore:     store,
+		metrics:          metrics.New(),
+	})
+
+	err = watcher.UpdateTracked(map[string][]string{"d1": {"s1"}, "d2": {"s1"}, "d3": {"s1"}}, map[string]time.Duration{"d1": 2 * time.Second, "d2": 2 * time.Second})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	checkAnomalies := func(t *testing.T, expected map[string]anomalystore.Status) {
+		list, _, err := store.ListAnomalies(anomalystore.AnomalyQuery{Handler: SilentDeviceHandlerName})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		actual := map[string]anomalystore.Status{}
+		for _, anomaly := range list {
+			actual[anomaly.Device] = anomaly.Status
+		}
+		if len(actual) != len(expected) {
+			t.Errorf("unexpected anomalies %#v", list)
+			return
+		}
+		for device, status := range expected {
+			if actual[device] != status {
+				t.Errorf("unexpected anomalies %#v", list)
+				return
+			}
+		}
+	}
+
+	t.Run("active devices", func(t *testing.T) {
+		time.Sleep(500 * time.Millisecond)
+		err = watcher.Check()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		checkAnomalies(t, map[string]anomalystore.Status{})
+	})
+
+	t.Run("silent device", func(t *testing.T) {
+		time.Sleep(2500 * time.Millisecond)
+		err = watcher.Seen("d2", "s1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = watcher.Check()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		checkAnomalies(t, map[string]anomalystore.Status{"d1": anomalystore.StatusOpen})
+	})
+
+	t.Run("recovered device", func(t *testing.T) {
+		err = watcher.Seen("d1", "s1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		time.Sleep(interval)
+		err = watcher.Check()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		checkAnomalies(t, map[string]anomalystore.Status{"d1": anomalystore.StatusResolved})
+	})
+
+	t.Run("check notifications", func(t *testing.T) {
+		notificationsMux.Lock()
+		defer notificationsMux.Unlock()
+		if len(notifications) != 2 ||
+			!strings.Contains(notifications[0], "silent_device anomaly detected for device d1") ||
+			!strings.Contains(notifications[1], "silent_device anomaly resolved for device d1") {
+			t.Errorf("unexpected notifications %#v", notifications)
+		}
+	})
+}
+
+func TestSilentDeviceWatcher_DeviceTimeout(t *testing.T) {
+	attributes := func(kv ...string) (result []models.Attribute) {
+		result = append(result, models.Attribute{Key: "anomaly-detector", Value: "true"})
+		for i := 0; i+1 < len(kv); i += 2 {
+			result = append(result, models.Attribute{Key: kv[i], Value: kv[i+1]})
+		}
+		return result
+	}
+	tests := []struct {
+		name           string
+		defaultTimeout time.Duration
+		attributes     []models.Attribute
+		expected       time.Duration
+		expectedOk     bool
+	}{
+		{name: "default", defaultTimeout: time.Hour, attributes: attributes(), expected: time.Hour, expectedOk: true},
+		{name: "no default", attributes: attributes()},
+		{name: "device timeout", attributes: attributes("anomaly-detector:silent_device:timeout", "15m"), expected: 15 * time.Minute, expectedOk: true},
+		{name: "device timeout overrides default", defaultTimeout: time.Hour, attributes: attributes("anomaly-detector:silent_device:timeout", "15m"), expected: 15 * time.Minute, expectedOk: true},
+		{name: "invalid device timeout", defaultTimeout: time.Hour, attributes: attributes("anomaly-detector:silent_device:timeout", "foo"), expected: time.Hour, expectedOk: true},
+		{name: "disabled", defaultTimeout: time.Hour, attributes: attributes("anomaly-detector:silent_device:timeout", "15m", "anomaly-detector:disabled", "silent_device")},
+		{name: "not enabled", defaultTimeout: time.Hour, attributes: attributes("anomaly-detector:enabled", "big_jump")},
+		{name: "detection disabled", defaultTimeout: time.Hour, attributes: []models.Attribute{{Key: "anomaly-detector:silent_device:timeout", Value: "15m"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			watcher := NewSilentDeviceWatcher(test.defaultTimeout, time.Minute, nil, HandlerInfo{})
+			actual, ok := watcher.DeviceTimeout("d1", ParseDeviceSettings("anomaly-detector", test.attributes))
+			if actual != test.expected && test.expectedOk || ok != test.expectedOk {
+				t.Errorf("DeviceTimeout() = %v, %v, want %v, %v", actual, ok, test.expected, test.expectedOk)
+			}
+		})
+	}
+}
+
+type testDeviceRepo struct {
+	devicerepo.Interface
+}
+
+func (this testDeviceRepo) ReadExtendedDevice(id string, _ string, _ devicerepomodel.AuthAction, _ bool) (result models.ExtendedDevice, err error, errCode int) {
+	return models.ExtendedDevice{Device: models.Device{Id: id, OwnerId: "owner"}, DisplayName: id}, nil, http.StatusOK
+}
@@ -23,8 +23,8 @@ import (
 	"fmt"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
 	"github.com/valkey-io/valkey-go"
-	"math/rand"
 	"slices"
+	"strconv"
 	"time"
 )
 
@@ -59,40 +59,135 @@ func (this *Store) Set(key string, value interface{}) (err error) {
 	return nil
 }
 
-func (this *HandlerInfo) storeAndListValues(handlerName string, deviceId string, serviceId string, size int, value interface{}) (result []interface{}, err error) {
+// BufferedValue is the element format of the value buffer
+type BufferedValue struct {
+	Timestamp int64           `json:"timestamp"` //unix timestamp in seconds of the event
+	Value     json.RawMessage `json:"value"`
+}
+
+// appendToRingBufferScript pushes ARGV[1] to the list KEYS[1], trims the list to ARGV[2] elements and returns the list (newest first)
+var appendToRingBufferScript = valkey.NewLuaScript(`
+redis.call('LPUSH', KEYS[1], ARGV[1])
+redis.call('LTRIM', KEYS[1], 0, tonumber(ARGV[2]) - 1)
+return redis.call('LRANGE', KEYS[1], 0, -1)
+`)
+
+// storeAndListValues appends the value to the buffer of the handler/device/service
+// and returns the newest size values with their timestamps (oldest first)
+// append, trim and read are executed atomically in one round trip
+// values stored before timestamps were recorded are returned with timestamp 0
+func (this *HandlerInfo) storeAndListValues(handlerName string, deviceId string, serviceId string, size int, value interface{}, timestamp int64) (values []interface{}, timestamps []int64, err error) {
 	key := fmt.Sprintf("%s_%s_%s", handlerName, deviceId, serviceId)
 
-	valueBuff, err := json.Marshal(value)
+	element, err := encodeBufferedValue(value, timestamp)
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("unable to marshal value: %w", err), model.ErrWillBeIgnored)
+		return nil, nil, errors.Join(fmt.Errorf("unable to marshal value: %w", err), model.ErrWillBeIgnored)
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
 
-	//add value to list
-	err = this.valKeyClient.Do(ctx, this.valKeyClient.B().Lpush().Key(key).Element(string(valueBuff)).Build()).Error()
+	resp := appendToRingBufferScript.Exec(ctx, this.valKeyClient, []string{key}, []string{element, strconv.Itoa(size)})
+	err = resp.Error()
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("unable to store value: %w", err), model.ErrWithRetry)
+		return nil, nil, errors.Join(fmt.Errorf("unable to store value: %w", err), model.ErrWithRetry)
 	}
-	//trim the list
-	//on average on every 5th call
-	if rand.Int()%5 == 0 {
-		err = this.valKeyClient.Do(ctx, this.valKeyClient.B().Ltrim().Key(key).Start(0).Stop(int64(size-1)).Build()).Error()
-		if err != nil {
-			return nil, errors.Join(fmt.Errorf("unable to trim store: %w", err), model.ErrWithRetry)
-		}
+	elements, err := resp.AsStrSlice()
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("unable to read list from store: %w", err), model.ErrWithRetry)
 	}
+	values, timestamps, err = decodeBufferedValues(elements)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("unable to unmarshal list from store: %w", err), model.ErrWillBeIgnored)
+	}
+	slices.Reverse(values)
+	slices.Reverse(timestamps)
+	return values, timestamps, nil
+}
+
+// appendToWindowBufferScript adds the member ARGV[2] with the score ARGV[1] to the sorted set KEYS[1],
+// removes members with scores below ARGV[3], sets the expiry to ARGV[4] seconds and returns the members with scores from ARGV[3] to ARGV[1]
+// newer members (e.g. if the value is a late event) are not returned
+var appendToWindowBufferScript = valkey.NewLuaScript(`
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[3])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[3], ARGV[1])
+`)
+
+// storeAndListWindow adds the value to the time-window buffer of the handler/device/service
+// and returns all values with timestamps within the window before the given timestamp (oldest first)
+// the buffer is a sorted set with the timestamps as scores; older values are removed and the key expires with the window
+// all steps are executed atomically in one round trip
+func (this *HandlerInfo) storeAndListWindow(handlerName string, deviceId string, serviceId string, window time.Duration, value interface{}, timestamp int64) (values []interface{}, timestamps []int64, err error) {
+	key := fmt.Sprintf("window_%s_%s_%s", handlerName, deviceId, serviceId)
+
+	element, err := encodeBufferedValue(value, timestamp)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("unable to marshal value: %w", err), model.ErrWillBeIgnored)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
 
-	//get list
-	resp := this.valKeyClient.Do(ctx, this.valKeyClient.B().Lrange().Key(key).Start(0).Stop(int64(size-1)).Build())
+	windowStart := strconv.FormatInt(timestamp-int64(window.Seconds()), 10)
+	resp := appendToWindowBufferScript.Exec(ctx, this.valKeyClient, []string{key}, []string{
+		strconv.FormatInt(timestamp, 10),
+		element,
+		windowStart,
+		strconv.FormatInt(int64(window.Seconds())+1, 10),
+	})
 	err = resp.Error()
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("unable to get value list from store: %w", err), model.ErrWithRetry)
+		return nil, nil, errors.Join(fmt.Errorf("unable to update window buffer: %w", err), model.ErrWithRetry)
 	}
-	err = valkey.DecodeSliceOfJSON(resp, &result)
+	elements, err := resp.AsStrSlice()
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("unable to unmarshal list from store: %w", err), model.ErrWillBeIgnored)
+		return nil, nil, errors.Join(fmt.Errorf("unable to read window buffer: %w", err), model.ErrWithRetry)
+	}
+	values, timestamps, err = decodeBufferedValues(elements)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("unable to unmarshal window buffer: %w", err), model.ErrWillBeIgnored)
+	}
+	return values, timestamps, nil
+}
+
+func encodeBufferedValue(value interface{}, timestamp int64) (string, error) {
+	valueBuff, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	elementBuff, err := json.Marshal(BufferedValue{Timestamp: timestamp, Value: valueBuff})
+	if err != nil {
+		return "", err
+	}
+	return string(elementBuff), nil
+}
+
+func decodeBufferedValues(elements []string) (values []interface{}, timestamps []int64, err error) {
+	for _, element := range elements {
+		value, timestamp, err := decodeBufferedValue(element)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, value)
+		timestamps = append(timestamps, timestamp)
+	}
+	return values, timestamps, nil
+}
+
+// decodeBufferedValue decodes a BufferedValue
+// elements of the previous format (plain json values) are returned with timestamp 0
+func decodeBufferedValue(element string) (value interface{}, timestamp int64, err error) {
+	fields := map[string]json.RawMessage{}
+	if json.Unmarshal([]byte(element), &fields) == nil && len(fields) == 2 && fields["timestamp"] != nil && fields["value"] != nil {
+		buffered := BufferedValue{}
+		err = json.Unmarshal([]byte(element), &buffered)
+		if err == nil {
+			err = json.Unmarshal(buffered.Value, &value)
+			return value, buffered.Timestamp, err
+		}
 	}
-	slices.Reverse(result)
-	return result, nil
+	err = json.Unmarshal([]byte(element), &value)
+	return value, 0, err
 }
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/tests/docker"
+	"github.com/valkey-io/valkey-go"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDecodeBufferedValues(t *testing.T) {
+	elements := []string{
+		`{"timestamp":1700000060,"value":12.5}`,
+		`{"timestamp":1700000000,"value":{"timestamp":"foo","value":1}}`,
+		`11.5`,
+		`{"value":1}`,
+	}
+	values, timestamps, err := decodeBufferedValues(elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedValues := []interface{}{12.5, map[string]interface{}{"timestamp": "foo", "value": 1.0}, 11.5, map[string]interface{}{"value": 1.0}}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("values = %#v, want %#v", values, expectedValues)
+	}
+	expectedTimestamps := []int64{1700000060, 1700000000, 0, 0}
+	if !reflect.DeepEqual(timestamps, expectedTimestamps) {
+		t.Errorf("timestamps = %#v, want %#v", timestamps, expectedTimestamps)
+	}
+
+	_, _, err = decodeBufferedValues([]string{`{"timestamp":`})
+	if err == nil {
+		t.Error("expected error for invalid json")
+	}
+}
+
+func TestStoreAndListValues(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, valKeyIp, err := docker.ValKey(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	valkeyClient, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{valKeyIp + ":6379"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer valkeyClient.Close()
+
+	info := &HandlerInfo{valKeyClient: valkeyClient}
+	for i := int64(1); i <= 10; i++ {
+		values, timestamps, err := info.storeAndListValues("test", "d1", "s1", 3, float64(i), i*60)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		wantValues := []interface{}{}
+		wantTimestamps := []int64{}
+		for j := max(1, i-2); j <= i; j++ {
+			wantValues = append(wantValues, float64(j))
+			wantTimestamps = append(wantTimestamps, j*60)
+		}
+		if !reflect.DeepEqual(values, wantValues) {
+			t.Errorf("step %v: values = %#v, want %#v", i, values, wantValues)
+		}
+		if !reflect.DeepEqual(timestamps, wantTimestamps) {
+			t.Errorf("step %v: timestamps = %#v, want %#v", i, timestamps, wantTimestamps)
+		}
+		length, err := valkeyClient.Do(ctx, valkeyClient.B().Llen().Key("test_d1_s1").Build()).AsInt64()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if length > 3 {
+			t.Errorf("step %v: buffer length %v exceeds size", i, length)
+		}
+	}
+}
+
+func TestStoreAndListWindow(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, valKeyIp, err := docker.ValKey(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	valkeyClient, err := valkey.NewClient(valkey.ClientOption{InitAddress: []string{valKeyIp + ":6379"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer valkeyClient.Close()
+
+	info := &HandlerInfo{valKeyClient: valkeyClient}
+	steps := []struct {
+		value          float64
+		timestamp      int64
+		wantValues     []interface{}
+		wantTimestamps []int64
+	}{
+		{value: 1, timestamp: 1000, wantValues: []interface{}{1.0}, wantTimestamps: []int64{1000}},
+		{value: 2, timestamp: 1030, wantValues: []interface{}{1.0, 2.0}, wantTimestamps: []int64{1000, 1030}},
+		{value: 3, timestamp: 1060, wantValues: []interface{}{1.0, 2.0, 3.0}, wantTimestamps: []int64{1000, 1030, 1060}},
+		{value: 4, timestamp: 1061, wantValues: []interface{}{2.0, 3.0, 4.0}, wantTimestamps: []int64{1030, 1060, 1061}},
+		{value: 5, timestamp: 1045, wantValues: []interface{}{2.0, 5.0}, wantTimestamps: []int64{1030, 1045}}, //late event: newer values are not in its window
+		{value: 7, timestamp: 1062, wantValues: []interface{}{2.0, 5.0, 3.0, 4.0, 7.0}, wantTimestamps: []int64{1030, 1045, 1060, 1061, 1062}},
+		{value: 6, timestamp: 2000, wantValues: []interface{}{6.0}, wantTimestamps: []int64{2000}},
+	}
+	for i, step := range steps {
+		values, timestamps, err := info.storeAndListWindow("test", "d1", "s1", time.Minute, step.value, step.timestamp)
+		if err != nil {
+			t.Errorf("step %v: %v", i, err)
+			return
+		}
+		if !reflect.DeepEqual(values, step.wantValues) {
+			t.Errorf("step %v: values = %#v, want %#v", i, values, step.wantValues)
+		}
+		if !reflect.DeepEqual(timestamps, step.wantTimestamps) {
+			t.Errorf("step %v: timestamps = %#v, want %#v", i, timestamps, step.wantTimestamps)
+		}
+	}
+
+	ttl, err := valkeyClient.Do(ctx, valkeyClient.B().Ttl().Key("window_test_d1_s1").Build()).AsInt64()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ttl <= 0 || ttl > 61 {
+		t.Errorf("unexpected ttl %v", ttl)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"log"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Supervisor keeps track of failing components (e.g. the kafka consumer or register reloads).
+// failed components mark the service as degraded until they recover.
+// if more failures than the budget happen within the budget window, the supervisor exits the process.
+type Supervisor struct {
+	mux        sync.Mutex
+	budget     int           //exit if more failures happen within window; disabled if 0
+	window     time.Duration //duration in which failures count against the budget
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	failures   []time.Time
+	attempts   map[string]int       //consecutive failures by component; used for the backoff
+	last       map[string]time.Time //last failure by component
+	degraded   map[string]string    //error of failed components, which did not recover yet
+	exit       func(reason string)
+}
+
+func NewSupervisor(budget int, window time.Duration) *Supervisor {
+	return &Supervisor{
+		budget:     budget,
+		window:     window,
+		minBackoff: time.Second,
+		maxBackoff: 5 * time.Minute,
+		attempts:   map[string]int{},
+		last:       map[string]time.Time{},
+		degraded:   map[string]string{},
+		exit: func(reason string) {
+			log.Fatalln("FATAL: failure budget exhausted:", reason)
+		},
+	}
+}
+
+// Failure marks the component as degraded and returns the duration to wait before the next recovery attempt
+// exits the process if the failure budget is exhausted
+func (this *Supervisor) Failure(component string, err error) (backoff time.Duration) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	log.Println("ERROR:", component, "failed:", err)
+	now := time.Now()
+	this.degraded[component] = err.Error()
+	if now.Sub(this.last[component]) > 2*this.maxBackoff {
+		this.attempts[component] = 0 //the component was stable for a while
+	}
+	this.attempts[component] = this.attempts[component] + 1
+	this.last[component] = now
+
+	this.failures = append(this.failures, now)
+	for len(this.failures) > 0 && now.Sub(this.failures[0]) > this.window {
+		this.failures = this.failures[1:]
+	}
+	if this.budget > 0 && len(this.failures) > this.budget {
+		this.exit(component + ": " + err.Error())
+	}
+
+	backoff = this.minBackoff
+	for i := 1; i < this.attempts[component] && backoff < this.maxBackoff; i++ {
+		backoff = backoff * 2
+	}
+	return min(backoff, this.maxBackoff)
+}
+
+// Recovered removes the degraded mark of the component
+// the backoff and the failure budget are not reset, so that a component, which recovers and fails again, still backs off;
+// the backoff of a component is reset by Failure, if the component did not fail for 2*maxBackoff
+func (this *Supervisor) Recovered(component string) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if _, ok := this.degraded[component]; ok {
+		log.Println("INFO:", component, "recovered")
+	}
+	delete(this.degraded, component)
+}
+
+func (this *Supervisor) Status() model.ServiceStatus {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	return model.ServiceStatus{
+		Degraded: len(this.degraded) > 0,
+		Failures: maps.Clone(this.degraded),
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSupervisor(t *testing.T) {
+	exited := ""
+	supervisor := NewSupervisor(3, time.Hour)
+	supervisor.exit = func(reason string) {
+		exited = reason
+	}
+
+	backoffs := []time.Duration{}
+	for i := 0; i < 3; i++ {
+		backoffs = append(backoffs, supervisor.Failure("a", errors.New("test")))
+	}
+	for i, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if backoffs[i] != expected {
+			t.Errorf("backoff %v: expected %v, got %v", i, expected, backoffs[i])
+		}
+	}
+	status := supervisor.Status()
+	if !status.Degraded || status.Failures["a"] != "test" {
+		t.Errorf("unexpected status %#v", status)
+	}
+	if exited != "" {
+		t.Error("unexpected exit", exited)
+	}
+
+	supervisor.Recovered("a")
+	if status = supervisor.Status(); status.Degraded || len(status.Failures) != 0 {
+		t.Errorf("unexpected status after recovery %#v", status)
+	}
+
+	supervisor.Failure("b", errors.New("test"))
+	if exited != "b: test" {
+		t.Errorf("expected exit after exhausted budget, got %#v", exited)
+	}
+}
+
+func TestSupervisorMaxBackoff(t *testing.T) {
+	supervisor := NewSupervisor(0, time.Hour)
+	var backoff time.Duration
+	for i := 0; i < 20; i++ {
+		backoff = supervisor.Failure("a", errors.New("test"))
+	}
+	if backoff != supervisor.maxBackoff {
+		t.Errorf("expected %v, got %v", supervisor.maxBackoff, backoff)
+	}
+}
+
+func TestSupervisorRecoveredKeepsBackoff(t *testing.T) {
+	supervisor := NewSupervisor(0, time.Hour)
+	supervisor.Failure("a", errors.New("test"))
+	supervisor.Recovered("a")
+	if backoff := supervisor.Failure("a", errors.New("test")); backoff != 2*time.Second {
+		t.Errorf("expected growing backoff after recovery, got %v", backoff)
+	}
+}
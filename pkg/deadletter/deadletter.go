@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+type Writer struct {
+	writer *kafka.Writer
+}
+
+// NewWriter creates a Writer for the dead-letter topic
+func NewWriter(kafkaUrl string, topic string) *Writer {
+	return &Writer{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(kafkaUrl),
+			Topic:                  topic,
+			MaxAttempts:            10,
+			BatchSize:              1,
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish sends the failed message with the error reason to the dead-letter topic
+func (this *Writer) Publish(msg model.ConsumerMessage, reason error) error {
+	return this.PublishLetter(NewDeadLetter(msg, reason))
+}
+
+// PublishLetter sends the dead letter to the dead-letter topic
+func (this *Writer) PublishLetter(letter model.DeadLetter) error {
+	value, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return this.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(letter.Topic),
+		Value: value,
+	})
+}
+
+// NewDeadLetter creates the dead letter of the failed message
+// the failed handlers are recorded, if reason contains a model.HandlerError
+func NewDeadLetter(msg model.ConsumerMessage, reason error) model.DeadLetter {
+	letter := model.DeadLetter{
+		Topic:          msg.Topic,
+		Key:            msg.Key,
+		Message:        string(msg.Message),
+		Timestamp:      msg.Timestamp,
+		Error:          reason.Error(),
+		ErrorTimestamp: time.Now().Unix(),
+	}
+	var handlerErr *model.HandlerError
+	if errors.As(reason, &handlerErr) {
+		letter.Handlers = handlerErr.Handlers
+	}
+	return letter
+}
+
+// StillDead creates the dead letter of a dead letter, which failed again to be re-injected
+// the handlers of the original letter are kept, if reason names no failed handlers
+func StillDead(letter model.DeadLetter, reason error) model.DeadLetter {
+	result := NewDeadLetter(model.ConsumerMessage{
+		Topic:     letter.Topic,
+		Key:       letter.Key,
+		Message:   []byte(letter.Message),
+		Timestamp: letter.Timestamp,
+	}, reason)
+	if len(result.Handlers) == 0 {
+		result.Handlers = letter.Handlers
+	}
+	return result
+}
+
+func (this *Writer) Close() error {
+	return this.writer.Close()
+}
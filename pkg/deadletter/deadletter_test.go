@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deadletter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"reflect"
+	"testing"
+)
+
+func TestNewDeadLetter(t *testing.T) {
+	msg := model.ConsumerMessage{Topic: "urn_infai_ses_service_1", Key: "d1", Message: []byte(`{}`), Timestamp: 10}
+	tests := []struct {
+		name     string
+		reason   error
+		handlers []string
+	}{
+		{
+			name:   "event error",
+			reason: errors.Join(errors.New("unable to unmarshal event"), model.ErrWillBeIgnored),
+		},
+		{
+			name:     "handler error",
+			reason:   &model.HandlerError{Handlers: []string{"h1", "h2"}, Err: errors.Join(errors.New("unable to marshal"), model.ErrWillBeIgnored)},
+			handlers: []string{"h1", "h2"},
+		},
+		{
+			name:     "wrapped handler error",
+			reason:   fmt.Errorf("wrapped: %w", &model.HandlerError{Handlers: []string{"h1"}, Err: model.ErrWillBeIgnored}),
+			handlers: []string{"h1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			letter := NewDeadLetter(msg, tt.reason)
+			if letter.Topic != msg.Topic || letter.Key != msg.Key || letter.Message != string(msg.Message) || letter.Timestamp != msg.Timestamp || letter.Error != tt.reason.Error() {
+				t.Errorf("unexpected dead letter %#v", letter)
+			}
+			if !reflect.DeepEqual(letter.Handlers, tt.handlers) {
+				t.Errorf("NewDeadLetter() handlers = %#v, want %#v", letter.Handlers, tt.handlers)
+			}
+			if !errors.Is(tt.reason, model.ErrWillBeIgnored) {
+				t.Error("reason should be ignored")
+			}
+		})
+	}
+}
+
+func TestStillDead(t *testing.T) {
+	letter := model.DeadLetter{Topic: "urn_infai_ses_service_1", Key: "d1", Message: `{}`, Timestamp: 10, Error: "old", ErrorTimestamp: 20, Handlers: []string{"h1", "h2"}}
+	tests := []struct {
+		name     string
+		reason   error
+		handlers []string
+	}{
+		{name: "keep handlers", reason: errors.New("unable to store"), handlers: []string{"h1", "h2"}},
+		{name: "failed handlers", reason: &model.HandlerError{Handlers: []string{"h2"}, Err: errors.New("unable to store")}, handlers: []string{"h2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := StillDead(letter, tt.reason)
+			if result.Topic != letter.Topic || result.Key != letter.Key || result.Message != letter.Message || result.Timestamp != letter.Timestamp {
+				t.Errorf("unexpected dead letter %#v", result)
+			}
+			if result.Error != tt.reason.Error() || result.ErrorTimestamp <= letter.ErrorTimestamp {
+				t.Errorf("error not updated %#v", result)
+			}
+			if !reflect.DeepEqual(result.Handlers, tt.handlers) {
+				t.Errorf("StillDead() handlers = %#v, want %#v", result.Handlers, tt.handlers)
+			}
+		})
+	}
+}
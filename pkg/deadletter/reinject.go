@@ -0,0 +1,131 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"github.com/segmentio/kafka-go"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ReinjectOptions struct {
+	KafkaUrl      string
+	Topic         string        //dead-letter topic
+	ConsumerGroup string        //committed offsets prevent repeated re-injection of the same dead letters; filters use their own group (see Group())
+	SourceTopic   string        //optional filter; only dead letters of this source topic are re-injected
+	ErrorContains string        //optional filter; only dead letters with errors containing this string are re-injected
+	DryRun        bool          //if true, dead letters are only logged
+	IdleTimeout   time.Duration //stop after this duration without new dead letters
+}
+
+// Reinject passes the dead letters to handle (see controller.Reprocess), until no dead letter is received for options.IdleTimeout
+// dead letters not matching the filters are skipped; they are committed only in the consumer group of the filters
+// if handle returns an error, the dead letter is published again with the new error (see StillDead), committed and counted as failed.
+// dead letters written after the start of the run (e.g. the still dead letters of this run) are not committed and left for the next run.
+func Reinject(ctx context.Context, options ReinjectOptions, handle func(letter model.DeadLetter) error) (reinjected int, skipped int, failed int, err error) {
+	log.Println("read dead letters with consumer group", options.Group())
+	start := time.Now().Unix()
+	var writer *Writer
+	if !options.DryRun {
+		writer = NewWriter(options.KafkaUrl, options.Topic)
+		defer writer.Close()
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        []string{options.KafkaUrl},
+		GroupID:        options.Group(),
+		Topic:          options.Topic,
+		StartOffset:    kafka.FirstOffset,
+		CommitInterval: 0, //synchronous commits
+		Logger:         log.New(io.Discard, "", 0),
+		ErrorLogger:    log.New(os.Stdout, "[KAFKA-ERROR] ", log.Default().Flags()),
+	})
+	defer reader.Close()
+
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, options.IdleTimeout)
+		m, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return reinjected, skipped, failed, nil
+		}
+		if err != nil {
+			return reinjected, skipped, failed, err
+		}
+		letter := model.DeadLetter{}
+		err = json.Unmarshal(m.Value, &letter)
+		if err != nil {
+			log.Println("WARNING: skip invalid dead letter", m.Offset, err)
+			skipped++
+		} else if letter.ErrorTimestamp >= start {
+			continue //no commit; later letters of the partition are newer too
+		} else if !options.matches(letter) {
+			skipped++
+		} else if options.DryRun {
+			log.Printf("dry-run: %v %v %v: %v\n", letter.Topic, letter.Handlers, letter.Error, letter.Message)
+			reinjected++
+			continue //no commit in dry-run
+		} else {
+			err = handle(letter)
+			if err != nil {
+				log.Println("ERROR: unable to reinject dead letter -> publish as still dead", m.Offset, letter.Topic, err)
+				err = writer.PublishLetter(StillDead(letter, err))
+				if err != nil {
+					return reinjected, skipped, failed, fmt.Errorf("unable to publish still dead letter %v of %v: %w", m.Offset, letter.Topic, err)
+				}
+				failed++
+			} else {
+				reinjected++
+			}
+		}
+		err = reader.CommitMessages(ctx, m)
+		if err != nil {
+			return reinjected, skipped, failed, err
+		}
+	}
+}
+
+// Group returns the consumer group of the re-injection
+// runs with filters use a consumer group derived from the filters, because the commit of skipped dead letters
+// would hide them from runs with other filters. a dead letter matching several filters is re-injected by each of them.
+func (this ReinjectOptions) Group() string {
+	if this.SourceTopic == "" && this.ErrorContains == "" {
+		return this.ConsumerGroup
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(this.SourceTopic + "\x00" + this.ErrorContains))
+	return this.ConsumerGroup + "-" + strconv.FormatUint(uint64(h.Sum32()), 16)
+}
+
+func (this ReinjectOptions) matches(letter model.DeadLetter) bool {
+	if this.SourceTopic != "" && letter.Topic != this.SourceTopic {
+		return false
+	}
+	if this.ErrorContains != "" && !strings.Contains(letter.Error, this.ErrorContains) {
+		return false
+	}
+	return true
+}
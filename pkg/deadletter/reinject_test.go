@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deadletter
+
+import (
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/model"
+	"testing"
+)
+
+func TestReinjectOptions_matches(t *testing.T) {
+	letter := model.DeadLetter{Topic: "urn_infai_ses_service_1", Error: "unable to marshal\nno matching output path"}
+	tests := []struct {
+		name    string
+		options ReinjectOptions
+		want    bool
+	}{
+		{name: "no filter", options: ReinjectOptions{}, want: true},
+		{name: "source topic", options: ReinjectOptions{SourceTopic: "urn_infai_ses_service_1"}, want: true},
+		{name: "other source topic", options: ReinjectOptions{SourceTopic: "urn_infai_ses_service_2"}, want: false},
+		{name: "error", options: ReinjectOptions{ErrorContains: "marshal"}, want: true},
+		{name: "other error", options: ReinjectOptions{ErrorContains: "panic"}, want: false},
+		{name: "both", options: ReinjectOptions{SourceTopic: "urn_infai_ses_service_1", ErrorContains: "output path"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.matches(letter); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReinjectOptions_Group(t *testing.T) {
+	base := ReinjectOptions{ConsumerGroup: "reinject"}
+	if base.Group() != "reinject" {
+		t.Errorf("unexpected group without filter %v", base.Group())
+	}
+	groups := map[string]bool{base.Group(): true}
+	for _, options := range []ReinjectOptions{
+		{ConsumerGroup: "reinject", SourceTopic: "a"},
+		{ConsumerGroup: "reinject", SourceTopic: "b"},
+		{ConsumerGroup: "reinject", ErrorContains: "a"},
+		{ConsumerGroup: "reinject", SourceTopic: "a", ErrorContains: "a"},
+	} {
+		group := options.Group()
+		if groups[group] {
+			t.Errorf("filters %#v share group %v with other filters", options, group)
+		}
+		groups[group] = true
+		if options.Group() != group {
+			t.Errorf("group of %#v is not stable", options)
+		}
+	}
+}
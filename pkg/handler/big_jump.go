@@ -21,30 +21,54 @@ import (
 	"math"
 )
 
+const BigJumpType = "big_jump"
+
 func init() {
+	RegisterType(BigJumpType, BigJumpHandler{})
+
 	/* Get Electricity Consumption, Electricity-->Total Subaspect, kWh */
-	Registry.Register("big_jump_anom_electricity_consumption_total_kwh", "urn:infai:ses:measuring-function:57dfd369-92db-462c-aca4-a767b52c972e", "urn:infai:ses:aspect:fdc999eb-d366-44e8-9d24-bfd48d5fece1", "urn:infai:ses:characteristic:3febed55-ba9b-43dc-8709-9c73bae3716e", 2, BigJumpHandler{})
+	Registry.MustRegister("big_jump_anom_electricity_consumption_total_kwh", "urn:infai:ses:measuring-function:57dfd369-92db-462c-aca4-a767b52c972e", "urn:infai:ses:aspect:fdc999eb-d366-44e8-9d24-bfd48d5fece1", "urn:infai:ses:characteristic:3febed55-ba9b-43dc-8709-9c73bae3716e", 2, BigJumpHandler{}, WithType(BigJumpType))
 
 	/* Get Volume, Water, Liter */
-	Registry.Register("big_jump_anom_volume_water_liter", "urn:infai:ses:measuring-function:cfa56e75-8e8f-4f0d-a3fa-ed2758422b2a", "urn:infai:ses:aspect:b8b3b549-3b01-4604-a727-20aa528c21c9", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, BigJumpHandler{})
+	Registry.MustRegister("big_jump_anom_volume_water_liter", "urn:infai:ses:measuring-function:cfa56e75-8e8f-4f0d-a3fa-ed2758422b2a", "urn:infai:ses:aspect:b8b3b549-3b01-4604-a727-20aa528c21c9", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, BigJumpHandler{}, WithType(BigJumpType))
 
 	/* Get Gas Consumption, Gas, Liter*/
-	Registry.Register("big_jump_anom_consumption_gas_liter", "urn:infai:ses:measuring-function:4daa591f-ad97-4e57-8014-aa3f5e552c3b", "urn:infai:ses:aspect:7ea324c1-48e4-419a-a499-325d79dac09f", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, BigJumpHandler{})
+	Registry.MustRegister("big_jump_anom_consumption_gas_liter", "urn:infai:ses:measuring-function:4daa591f-ad97-4e57-8014-aa3f5e552c3b", "urn:infai:ses:aspect:7ea324c1-48e4-419a-a499-325d79dac09f", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, BigJumpHandler{}, WithType(BigJumpType))
 }
 
+/* Distance to the mean in standard deviations, from which on a difference is a big jump*/
+const BigJumpSigma = 5
+
+/* Distance to the mean in standard deviations, from which on a big jump is critical*/
+const BigJumpCriticalSigma = 10
+
 type BigJumpHandler struct{}
 
+func (this BigJumpHandler) ParameterDefinitions() ParameterDefinitions {
+	return ParameterDefinitions{
+		"sigma":          {Type: FloatParameter, Default: float64(BigJumpSigma), Validate: Positive},
+		"critical_sigma": {Type: FloatParameter, Default: float64(BigJumpCriticalSigma), Validate: Positive},
+	}
+}
+
 func (this BigJumpHandler) Handle(context Context, values []interface{}) (anomaly bool, description string, err error) {
+	result, err := this.HandleWithScore(context, values)
+	return result.Anomaly, result.Description, err
+}
+
+// HandleWithScore rates big jumps by their distance to the mean in standard deviations
+func (this BigJumpHandler) HandleWithScore(context Context, values []interface{}) (result Result, err error) {
+	parameters := context.Parameters.WithDefaults(this.ParameterDefinitions())
 	castValues, err := CastList[float64](values)
 	if err != nil {
-		return false, "", err
+		return result, err
 	}
 	log.Println("Values:", castValues)
 
 	latestDifference := castValues[1] - castValues[0]
 
 	if latestDifference == 0.0 {
-		return false, "", err
+		return result, nil
 	}
 
 	/* Std deviation of differences between consecutive meter values*/
@@ -68,7 +92,8 @@ func (this BigJumpHandler) Handle(context Context, values []interface{}) (anomal
 		NumDatepoints = 0.0
 	}
 
-	var bigJump bool = latestDifference > CurrentMean+5*CurrentStddev
+	var bigJump bool = latestDifference > CurrentMean+parameters.Float("sigma")*CurrentStddev
+	score := SigmaScore(latestDifference-CurrentMean, CurrentStddev)
 
 	CurrentStddev = UpdateStddev(latestDifference, CurrentStddev, CurrentMean, NumDatepoints)
 	context.Store.Set(context.PrepareKey("big_jump", "stddev"), CurrentStddev)
@@ -81,9 +106,14 @@ func (this BigJumpHandler) Handle(context Context, values []interface{}) (anomal
 
 	if bigJump {
 		log.Println("Meter reading had big jump.")
-		return true, "Meter reading had big jump.", nil
+		return Result{
+			Anomaly:     true,
+			Description: "Meter reading had big jump.",
+			Score:       score,
+			Severity:    SigmaSeverity(score, parameters.Float("critical_sigma")),
+		}, nil
 	}
-	return false, "", nil
+	return result, nil
 }
 
 /*Sample Update of standard deviation*/
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Types lists the known handler types, which may be used in handler configs
+var Types = map[string]Handler{}
+
+// RegisterType makes a handler type usable in handler configs
+// handlers are shared between entries of the same type; settings are passed in Context.Parameters (see ParameterizedHandler)
+func RegisterType(name string, handler Handler) {
+	Types[name] = handler
+}
+
+type HandlerConfig struct {
+	Name           string                 `json:"name"`
+	Type           string                 `json:"type"`
+	Function       string                 `json:"function"`
+	Aspect         string                 `json:"aspect"`
+	Characteristic string                 `json:"characteristic"`
+	BufferSize     int                    `json:"buffer_size"`
+	BufferWindow   string                 `json:"buffer_window,omitempty"` //optional duration (e.g. "24h"); see WithBufferWindow()
+	AutoResolve    bool                   `json:"auto_resolve"`
+	Severity       Severity               `json:"severity,omitempty"`   //default severity; defaults to warning
+	Mode           Mode                   `json:"mode,omitempty"`       //active, shadow or disabled; defaults to active
+	Parameters     map[string]interface{} `json:"parameters,omitempty"` //see ParameterDefinitions of the handler type
+}
+
+// LoadConfig registers the handlers described in the json file at location
+// the file contains a list of HandlerConfig; entries with the name of an existing entry replace it
+func (this *Register) LoadConfig(location string) error {
+	file, err := os.Open(location)
+	if err != nil {
+		return fmt.Errorf("unable to open handler config: %w", err)
+	}
+	defer file.Close()
+	configs := []HandlerConfig{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	err = decoder.Decode(&configs)
+	if err != nil {
+		return fmt.Errorf("unable to decode handler config: %w", err)
+	}
+	for _, config := range configs {
+		err = this.RegisterConfig(config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *Register) RegisterConfig(config HandlerConfig) error {
+	if config.Name == "" {
+		return errors.New("missing name in handler config")
+	}
+	if config.Function == "" || config.Aspect == "" || config.Characteristic == "" {
+		return fmt.Errorf("missing function, aspect or characteristic in handler config %v", config.Name)
+	}
+	if config.BufferSize <= 0 {
+		return fmt.Errorf("buffer_size of handler config %v must be greater than 0", config.Name)
+	}
+	handler, ok := Types[config.Type]
+	if !ok {
+		return fmt.Errorf("unknown handler type %#v in handler config %v", config.Type, config.Name)
+	}
+	options := []Option{WithType(config.Type), WithParameters(config.Parameters)}
+	if existing, exists := this.entries[config.Name]; exists {
+		log.Println("WARNING: handler config replaces existing handler", config.Name)
+		if !existing.OwnState {
+			log.Println("WARNING: handler config keeps the shared state of the replaced handler", config.Name)
+		} else {
+			options = append(options, WithOwnState())
+		}
+	} else {
+		options = append(options, WithOwnState())
+	}
+	if config.BufferWindow != "" {
+		window, err := time.ParseDuration(config.BufferWindow)
+		if err != nil {
+			return fmt.Errorf("invalid buffer_window in handler config %v: %w", config.Name, err)
+		}
+		if window <= 0 {
+			return fmt.Errorf("buffer_window of handler config %v must be greater than 0", config.Name)
+		}
+		options = append(options, WithBufferWindow(window))
+	}
+	if config.AutoResolve {
+		options = append(options, WithAutoResolve())
+	}
+	if config.Severity != "" {
+		options = append(options, WithSeverity(config.Severity))
+	}
+	if config.Mode != "" {
+		options = append(options, WithMode(config.Mode))
+	}
+	return this.Register(config.Name, config.Function, config.Aspect, config.Characteristic, config.BufferSize, handler, options...)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegister_LoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []Entry
+		wantErr bool
+	}{
+		{
+			name:   "big_jump",
+			config: `[{"name": "test", "type": "big_jump", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "parameters": {"sigma": 4}}]`,
+			want:   []Entry{{Name: "test", Type: BigJumpType, Function: "f", Aspect: "a", Characteristic: "c", BufferSize: 2, Handler: BigJumpHandler{}, Parameters: Parameters{"sigma": 4.0, "critical_sigma": 10.0}, Severity: SeverityWarning, Mode: ModeActive, OwnState: true}},
+		},
+		{
+			name:   "flatline",
+			config: `[{"name": "test", "type": "flatline", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "auto_resolve": true, "severity": "info", "parameters": {"max_unchanged_events": 5, "max_unchanged_duration": "1h"}}]`,
+			want:   []Entry{{Name: "test", Type: FlatlineType, Function: "f", Aspect: "a", Characteristic: "c", BufferSize: 2, AutoResolve: true, Handler: FlatlineHandler{}, Parameters: Parameters{"max_unchanged_events": int64(5), "max_unchanged_duration": time.Hour}, Severity: SeverityInfo, Mode: ModeActive, OwnState: true}},
+		},
+		{
+			name:   "buffer_window",
+			config: `[{"name": "test", "type": "jump_back", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 1, "buffer_window": "24h"}]`,
+			want:   []Entry{{Name: "test", Type: JumpBackType, Function: "f", Aspect: "a", Characteristic: "c", BufferSize: 1, BufferWindow: 24 * time.Hour, Handler: JumpBackHandler{}, Parameters: Parameters{}, Severity: SeverityWarning, Mode: ModeActive, OwnState: true}},
+		},
+		{
+			name:   "shadow mode",
+			config: `[{"name": "test", "type": "jump_back", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "mode": "shadow"}]`,
+			want:   []Entry{{Name: "test", Type: JumpBackType, Function: "f", Aspect: "a", Characteristic: "c", BufferSize: 2, Handler: JumpBackHandler{}, Parameters: Parameters{}, Severity: SeverityWarning, Mode: ModeShadow, OwnState: true}},
+		},
+		{
+			name:    "invalid mode",
+			config:  `[{"name": "test", "type": "jump_back", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "mode": "dry-run"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid buffer_window",
+			config:  `[{"name": "test", "type": "jump_back", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 1, "buffer_window": "-1h"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			config:  `[{"name": "test", "type": "unknown", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing buffer_size",
+			config:  `[{"name": "test", "type": "big_jump", "function": "f", "aspect": "a", "characteristic": "c"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			config:  `[{"name": "test", "type": "big_jump", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "parameters": {"foo": 1}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "parameters for handler without parameters",
+			config:  `[{"name": "test", "type": "jump_back", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "parameters": {"sigma": 1}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid sigma",
+			config:  `[{"name": "test", "type": "big_jump", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "parameters": {"sigma": -1}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid severity",
+			config:  `[{"name": "test", "type": "jump_back", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "severity": "urgent"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid flatline duration",
+			config:  `[{"name": "test", "type": "flatline", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "parameters": {"max_unchanged_duration": "foo"}}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := filepath.Join(t.TempDir(), "handlers.json")
+			err := os.WriteFile(location, []byte(tt.config), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			register := NewRegister()
+			err = register.LoadConfig(location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := register.List(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfig() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// a handler config, which replaces a built-in entry, keeps the state of the built-in entry
+func TestRegister_LoadConfigReplacesBuiltIn(t *testing.T) {
+	register := NewRegister()
+	err := register.Register("test", "f", "a", "c", 2, BigJumpHandler{}, WithType(BigJumpType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	location := filepath.Join(t.TempDir(), "handlers.json")
+	err = os.WriteFile(location, []byte(`[{"name": "test", "type": "big_jump", "function": "f", "aspect": "a", "characteristic": "c", "buffer_size": 2, "parameters": {"sigma": 4}}]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = register.LoadConfig(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := register.List()[0]
+	if entry.OwnState || entry.Parameters.Float("sigma") != 4 {
+		t.Errorf("unexpected entry %#v", entry)
+	}
+}
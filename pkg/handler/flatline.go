@@ -0,0 +1,91 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+const FlatlineType = "flatline"
+
+func init() {
+	RegisterType(FlatlineType, FlatlineHandler{})
+
+	/* Get Electricity Consumption, Electricity-->Total Subaspect, kWh */
+	Registry.MustRegister("flatline_anom_electricity_consumption_total_kwh", "urn:infai:ses:measuring-function:57dfd369-92db-462c-aca4-a767b52c972e", "urn:infai:ses:aspect:fdc999eb-d366-44e8-9d24-bfd48d5fece1", "urn:infai:ses:characteristic:3febed55-ba9b-43dc-8709-9c73bae3716e", 2, FlatlineHandler{}, WithAutoResolve(), WithType(FlatlineType))
+
+	/* Get Volume, Water, Liter */
+	Registry.MustRegister("flatline_anom_volume_water_liter", "urn:infai:ses:measuring-function:cfa56e75-8e8f-4f0d-a3fa-ed2758422b2a", "urn:infai:ses:aspect:b8b3b549-3b01-4604-a727-20aa528c21c9", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, FlatlineHandler{}, WithAutoResolve(), WithType(FlatlineType))
+
+	/* Get Gas Consumption, Gas, Liter*/
+	Registry.MustRegister("flatline_anom_consumption_gas_liter", "urn:infai:ses:measuring-function:4daa591f-ad97-4e57-8014-aa3f5e552c3b", "urn:infai:ses:aspect:7ea324c1-48e4-419a-a499-325d79dac09f", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, FlatlineHandler{}, WithAutoResolve(), WithType(FlatlineType))
+}
+
+// FlatlineHandler reports meters which are stuck at the same value
+// the newest value is compared to the value of the previous events
+//
+//	max_unchanged_events: anomaly if at least this many consecutive events have the same value; ignored if 0
+//	max_unchanged_duration: anomaly if the value did not change for at least this duration; ignored if 0
+type FlatlineHandler struct{}
+
+func (this FlatlineHandler) ParameterDefinitions() ParameterDefinitions {
+	return ParameterDefinitions{
+		"max_unchanged_events":   {Type: IntParameter, Default: int64(0), Validate: NonNegative},
+		"max_unchanged_duration": {Type: DurationParameter, Default: 72 * time.Hour, Validate: NonNegative},
+	}
+}
+
+type FlatlineState struct {
+	Value float64 `json:"value"`
+	Since int64   `json:"since"` //unix timestamp in seconds of the first event with this value
+	Count int     `json:"count"` //count of consecutive events with this value
+}
+
+func (this FlatlineHandler) Handle(context Context, values []interface{}) (anomaly bool, description string, err error) {
+	castValues, err := CastList[float64](values)
+	if err != nil {
+		return false, "", err
+	}
+	latest := castValues[len(castValues)-1]
+
+	key := context.PrepareKey("flatline", "state")
+	var state FlatlineState
+	err = context.Store.Get(key, &state)
+	if err != nil || state.Count == 0 || state.Value != latest {
+		state = FlatlineState{Value: latest, Since: context.Timestamp, Count: 0}
+	}
+	state.Count = state.Count + 1
+	err = context.Store.Set(key, state)
+	if err != nil {
+		return false, "", err
+	}
+
+	parameters := context.Parameters.WithDefaults(this.ParameterDefinitions())
+	maxUnchangedEvents := parameters.Int("max_unchanged_events")
+	maxUnchangedDuration := parameters.Duration("max_unchanged_duration")
+	unchangedDuration := time.Duration(context.Timestamp-state.Since) * time.Second
+	stuck := (maxUnchangedEvents > 0 && int64(state.Count) >= maxUnchangedEvents) ||
+		(maxUnchangedDuration > 0 && unchangedDuration >= maxUnchangedDuration)
+	if !stuck {
+		return false, "", nil
+	}
+	description = fmt.Sprintf("Meter reading did not change since %v.", time.Unix(state.Since, 0).UTC().Format(time.RFC3339))
+	log.Println(description)
+	return true, description, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlatlineHandler_Handle(t *testing.T) {
+	type step struct {
+		values          []interface{}
+		timestamp       int64
+		wantAnomaly     bool
+		wantDescription string
+	}
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		steps      []step
+	}{
+		{
+			name:       "max_unchanged_events",
+			parameters: map[string]interface{}{"max_unchanged_events": 3, "max_unchanged_duration": "0s"},
+			steps: []step{
+				{values: []interface{}{1.0, 2.0}, timestamp: 60},
+				{values: []interface{}{2.0, 2.0}, timestamp: 120},
+				{values: []interface{}{2.0, 2.0}, timestamp: 180, wantAnomaly: true, wantDescription: "Meter reading did not change since 1970-01-01T00:01:00Z."},
+				{values: []interface{}{2.0, 2.0}, timestamp: 240, wantAnomaly: true, wantDescription: "Meter reading did not change since 1970-01-01T00:01:00Z."},
+				{values: []interface{}{2.0, 2.1}, timestamp: 300},
+				{values: []interface{}{2.1, 2.1}, timestamp: 360},
+			},
+		},
+		{
+			name:       "max_unchanged_duration",
+			parameters: map[string]interface{}{"max_unchanged_duration": time.Hour},
+			steps: []step{
+				{values: []interface{}{1.0, 2.0}, timestamp: 0},
+				{values: []interface{}{2.0, 2.0}, timestamp: 1800},
+				{values: []interface{}{2.0, 2.0}, timestamp: 3599},
+				{values: []interface{}{2.0, 2.0}, timestamp: 3600, wantAnomaly: true, wantDescription: "Meter reading did not change since 1970-01-01T00:00:00Z."},
+				{values: []interface{}{2.0, 3.0}, timestamp: 7200},
+				{values: []interface{}{3.0, 3.0}, timestamp: 9000},
+			},
+		},
+		{
+			name:       "disabled",
+			parameters: map[string]interface{}{"max_unchanged_duration": "0s"},
+			steps: []step{
+				{values: []interface{}{2.0, 2.0}, timestamp: 0},
+				{values: []interface{}{2.0, 2.0}, timestamp: 999999},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &TestStore{}
+			handler := FlatlineHandler{}
+			parameters, err := handler.ParameterDefinitions().Parse(tt.parameters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, s := range tt.steps {
+				gotAnomaly, gotDescription, err := handler.Handle(Context{DeviceId: "test-device", ServiceId: "test-service", Timestamp: s.timestamp, Store: store, Parameters: parameters}, s.values)
+				if err != nil {
+					t.Errorf("Handle() step %v error = %v", i, err)
+					return
+				}
+				if gotAnomaly != s.wantAnomaly {
+					t.Errorf("Handle() step %v gotAnomaly = %v, want %v", i, gotAnomaly, s.wantAnomaly)
+				}
+				if gotDescription != s.wantDescription {
+					t.Errorf("Handle() step %v gotDescription = %v, want %v", i, gotDescription, s.wantDescription)
+				}
+			}
+		})
+	}
+}
@@ -29,13 +29,23 @@ type Handler interface {
 }
 
 type Context struct {
-	DeviceId  string
-	ServiceId string
-	Store     Store
+	Entry      string //name of the registered entry, if it has its own state (see WithOwnState); set by Entry.Handle/Entry.Evaluate and used by PrepareKey
+	DeviceId   string
+	ServiceId  string
+	Timestamp  int64   //unix timestamp in seconds of the newest value
+	Timestamps []int64 //unix timestamps in seconds of the values passed to Handle (same order); 0 for values buffered before timestamps were recorded
+	Store      Store
+	Parameters Parameters //parameters of the handler entry; defaults if not set in the registration
 }
 
+// PrepareKey returns the store key for state of the handler on the device/service
+// if Entry is set, the key contains the entry name, so entries of the same handler type (e.g. with other parameters or in shadow mode) do not share state
+// otherwise the key is handlerstore_<handlerName>_<device>_<service>_<subKey>, which is used by built-in entries to keep existing state
 func (this Context) PrepareKey(handlerName string, subKey string) string {
-	return fmt.Sprintf("handlerstore_%s_%s_%s_%s", handlerName, this.DeviceId, this.ServiceId, subKey)
+	if this.Entry == "" {
+		return fmt.Sprintf("handlerstore_%s_%s_%s_%s", handlerName, this.DeviceId, this.ServiceId, subKey)
+	}
+	return fmt.Sprintf("handlerstore_%s_%s_%s_%s_%s", handlerName, this.Entry, this.DeviceId, this.ServiceId, subKey)
 }
 
 type Store interface {
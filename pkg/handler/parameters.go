@@ -0,0 +1,212 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	_ "time/tzdata" //locations are available without the zoneinfo of the system (e.g. in the alpine image)
+)
+
+type ParameterType string
+
+const (
+	FloatParameter    ParameterType = "float"    //stored as float64
+	IntParameter      ParameterType = "int"      //stored as int64
+	DurationParameter ParameterType = "duration" //stored as time.Duration; accepts strings like "72h" or seconds as number
+	LocationParameter ParameterType = "location" //stored as *time.Location; accepts IANA time zone names like "Europe/Berlin"
+)
+
+type ParameterDefinition struct {
+	Type     ParameterType
+	Default  interface{}                   //must match the stored type of Type
+	Validate func(value interface{}) error //optional; receives the converted value
+}
+
+type ParameterDefinitions map[string]ParameterDefinition
+
+// ParameterizedHandler is implemented by handlers which accept parameters.
+// the parameters of an entry are passed to the handler in Context.Parameters
+type ParameterizedHandler interface {
+	Handler
+	ParameterDefinitions() ParameterDefinitions
+}
+
+// Parameters holds validated parameter values by name; use the typed getters to read them
+type Parameters map[string]interface{}
+
+func (this Parameters) Float(name string) float64 {
+	result, _ := this[name].(float64)
+	return result
+}
+
+func (this Parameters) Int(name string) int64 {
+	result, _ := this[name].(int64)
+	return result
+}
+
+func (this Parameters) Duration(name string) time.Duration {
+	result, _ := this[name].(time.Duration)
+	return result
+}
+
+// Location returns time.Local, if the parameter is not set
+func (this Parameters) Location(name string) *time.Location {
+	result, _ := this[name].(*time.Location)
+	if result == nil {
+		return time.Local
+	}
+	return result
+}
+
+// WithDefaults returns the parameters with the defaults of the definitions for missing values,
+// e.g. if the handler is called with nil parameters, without an entry of a register
+func (this Parameters) WithDefaults(definitions ParameterDefinitions) Parameters {
+	result := definitions.Defaults()
+	for name, value := range this {
+		result[name] = value
+	}
+	return result
+}
+
+func (this ParameterDefinitions) Defaults() Parameters {
+	result := Parameters{}
+	for name, definition := range this {
+		result[name] = definition.Default
+	}
+	return result
+}
+
+// Parse returns the defaults, overwritten by the converted and validated raw values
+func (this ParameterDefinitions) Parse(raw map[string]interface{}) (Parameters, error) {
+	return this.Apply(this.Defaults(), raw)
+}
+
+// Apply returns a copy of base, overwritten by the converted and validated raw values
+// raw values may be strings (e.g. from device attributes), json numbers or values of the stored type
+func (this ParameterDefinitions) Apply(base Parameters, raw map[string]interface{}) (result Parameters, err error) {
+	result = Parameters{}
+	for name, value := range base {
+		result[name] = value
+	}
+	for name, value := range raw {
+		definition, ok := this[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %#v", name)
+		}
+		converted, err := definition.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %#v: %w", name, err)
+		}
+		if definition.Validate != nil {
+			err = definition.Validate(converted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter %#v: %w", name, err)
+			}
+		}
+		result[name] = converted
+	}
+	return result, nil
+}
+
+func (this ParameterDefinition) convert(value interface{}) (interface{}, error) {
+	if number, ok := value.(json.Number); ok {
+		value = number.String()
+	}
+	switch this.Type {
+	case FloatParameter:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+	case IntParameter:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case int:
+			return int64(v), nil
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, errors.New("expected integer")
+			}
+			return int64(v), nil
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+	case DurationParameter:
+		switch v := value.(type) {
+		case time.Duration:
+			return v, nil
+		case float64:
+			return time.Duration(v * float64(time.Second)), nil
+		case int:
+			return time.Duration(v) * time.Second, nil
+		case int64:
+			return time.Duration(v) * time.Second, nil
+		case string:
+			return time.ParseDuration(v)
+		}
+	case LocationParameter:
+		switch v := value.(type) {
+		case *time.Location:
+			return v, nil
+		case string:
+			return time.LoadLocation(v)
+		}
+	default:
+		return nil, fmt.Errorf("unknown parameter type %#v", this.Type)
+	}
+	return nil, fmt.Errorf("unexpected value %#v for %v parameter", value, this.Type)
+}
+
+// Positive may be used as ParameterDefinition.Validate for numeric and duration parameters
+func Positive(value interface{}) error {
+	if toFloat(value) <= 0 {
+		return errors.New("must be greater than 0")
+	}
+	return nil
+}
+
+// NonNegative may be used as ParameterDefinition.Validate for numeric and duration parameters
+func NonNegative(value interface{}) error {
+	if toFloat(value) < 0 {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case time.Duration:
+		return float64(v)
+	}
+	return math.NaN()
+}
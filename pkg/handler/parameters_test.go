@@ -0,0 +1,105 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParameterDefinitions_Parse(t *testing.T) {
+	definitions := ParameterDefinitions{
+		"sigma":    {Type: FloatParameter, Default: 5.0, Validate: Positive},
+		"count":    {Type: IntParameter, Default: int64(10), Validate: NonNegative},
+		"duration": {Type: DurationParameter, Default: time.Hour},
+	}
+	tests := []struct {
+		name    string
+		raw     map[string]interface{}
+		want    Parameters
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			raw:  nil,
+			want: Parameters{"sigma": 5.0, "count": int64(10), "duration": time.Hour},
+		},
+		{
+			name: "strings",
+			raw:  map[string]interface{}{"sigma": "4.5", "count": "3", "duration": "2h"},
+			want: Parameters{"sigma": 4.5, "count": int64(3), "duration": 2 * time.Hour},
+		},
+		{
+			name: "json",
+			raw:  map[string]interface{}{"sigma": json.Number("4"), "count": 3.0, "duration": 60.0},
+			want: Parameters{"sigma": 4.0, "count": int64(3), "duration": time.Minute},
+		},
+		{
+			name:    "unknown",
+			raw:     map[string]interface{}{"foo": 1.0},
+			wantErr: true,
+		},
+		{
+			name:    "not an integer",
+			raw:     map[string]interface{}{"count": 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "invalid string",
+			raw:     map[string]interface{}{"sigma": "high"},
+			wantErr: true,
+		},
+		{
+			name:    "validation",
+			raw:     map[string]interface{}{"sigma": 0.0},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := definitions.Parse(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameters_WithDefaults(t *testing.T) {
+	definitions := BigJumpHandler{}.ParameterDefinitions()
+	tests := []struct {
+		name       string
+		parameters Parameters
+		want       Parameters
+	}{
+		{name: "nil", parameters: nil, want: Parameters{"sigma": float64(BigJumpSigma), "critical_sigma": float64(BigJumpCriticalSigma)}},
+		{name: "partial", parameters: Parameters{"sigma": 2.0}, want: Parameters{"sigma": 2.0, "critical_sigma": float64(BigJumpCriticalSigma)}},
+		{name: "complete", parameters: Parameters{"sigma": 2.0, "critical_sigma": 3.0}, want: Parameters{"sigma": 2.0, "critical_sigma": 3.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parameters.WithDefaults(definitions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithDefaults() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
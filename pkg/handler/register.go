@@ -16,21 +16,150 @@
 
 package handler
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 var Registry = NewRegister()
 
+// Mode controls how the service uses the anomalies of an entry
+type Mode string
+
+const (
+	ModeActive   Mode = "active"   //anomalies are stored and notified
+	ModeShadow   Mode = "shadow"   //anomalies are stored as shadow anomalies and never notified
+	ModeDisabled Mode = "disabled" //the handler is not called
+)
+
+var ErrInvalidMode = errors.New("invalid mode; expected active, shadow or disabled")
+
+func (this Mode) Validate() error {
+	switch this {
+	case ModeActive, ModeShadow, ModeDisabled:
+		return nil
+	default:
+		return ErrInvalidMode
+	}
+}
+
 type Entry struct {
 	Name           string
+	Type           string //optional; name of the handler type, if the entry was created from a HandlerConfig or WithType()
 	Function       string
 	Aspect         string
 	Characteristic string
-	BufferSize     int
+	BufferSize     int           //count of values passed to the handler; minimal count of values within the window, if BufferWindow is set
+	BufferWindow   time.Duration //optional; if set, all values of this duration before the newest value are passed to the handler
 	Handler        Handler
+	AutoResolve    bool       //if true, a result without anomaly resolves the open anomaly of the handler/device/service
+	Parameters     Parameters //validated parameters of a ParameterizedHandler, passed to the handler in Context.Parameters
+	Severity       Severity   //default severity of detected anomalies, used if the handler does not set one (see ScoringHandler)
+	Mode           Mode       //defaults to ModeActive
+	OwnState       bool       //if true, the state of the entry is stored under its name (see Context.PrepareKey); otherwise it is shared by the entries of the handler on a device/service
+}
+
+type Option func(entry *Entry)
+
+// WithType sets the name of the handler type (see RegisterType)
+func WithType(handlerType string) Option {
+	return func(entry *Entry) {
+		entry.Type = handlerType
+	}
+}
+
+// WithParameters sets raw parameter values, which overwrite the defaults of a ParameterizedHandler
+func WithParameters(parameters map[string]interface{}) Option {
+	return func(entry *Entry) {
+		entry.Parameters = parameters
+	}
+}
+
+// WithSeverity sets the default severity of anomalies detected by the handler (default SeverityWarning)
+func WithSeverity(severity Severity) Option {
+	return func(entry *Entry) {
+		entry.Severity = severity
+	}
+}
+
+// WithMode sets the mode of the entry (default ModeActive);
+// e.g. ModeShadow to compare the anomalies of a new handler with existing ones, before device owners are notified
+func WithMode(mode Mode) Option {
+	return func(entry *Entry) {
+		entry.Mode = mode
+	}
+}
+
+// WithBufferWindow lets the handler receive all values within the window before the newest value,
+// instead of the last bufferSize values. bufferSize becomes the minimal count of values, the handler needs to be called.
+func WithBufferWindow(window time.Duration) Option {
+	return func(entry *Entry) {
+		entry.BufferWindow = window
+	}
+}
+
+// WithAutoResolve lets the handler signal recovery:
+// the first result without anomaly after an anomaly resolves the open anomaly of the device/service
+func WithAutoResolve() Option {
+	return func(entry *Entry) {
+		entry.AutoResolve = true
+	}
+}
+
+// WithOwnState separates the state of the entry from other entries of the same handler (see Context.PrepareKey);
+// used for entries of handler configs, which may e.g. run the handler with other parameters or in shadow mode.
+// entries without own state keep the state keys used before entries could be configured.
+func WithOwnState() Option {
+	return func(entry *Entry) {
+		entry.OwnState = true
+	}
 }
 
 func (this *Entry) Handle(context Context, values []interface{}) (anomaly bool, description string, err error) {
+	if context.Parameters == nil {
+		context.Parameters = this.Parameters
+	}
+	if context.Entry == "" && this.OwnState {
+		context.Entry = this.Name
+	}
 	return this.Handler.Handle(context, values)
 }
 
+// Evaluate calls the handler and returns its result with score and severity;
+// handlers without ScoringHandler receive a score of 1 for anomalies and the default severity of the entry
+func (this *Entry) Evaluate(context Context, values []interface{}) (result Result, err error) {
+	if context.Parameters == nil {
+		context.Parameters = this.Parameters
+	}
+	if context.Entry == "" && this.OwnState {
+		context.Entry = this.Name
+	}
+	if scoring, ok := this.Handler.(ScoringHandler); ok {
+		result, err = scoring.HandleWithScore(context, values)
+	} else {
+		result.Anomaly, result.Description, err = this.Handler.Handle(context, values)
+		if result.Anomaly {
+			result.Score = 1
+		}
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if result.Severity == "" {
+		result.Severity = this.Severity
+	}
+	return result, nil
+}
+
+// ParameterDefinitions returns the parameter definitions of the handler (nil if the handler accepts no parameters)
+func (this *Entry) ParameterDefinitions() ParameterDefinitions {
+	if parameterized, ok := this.Handler.(ParameterizedHandler); ok {
+		return parameterized.ParameterDefinitions()
+	}
+	return nil
+}
+
 type Register struct {
 	entries map[string]Entry
 }
@@ -46,14 +175,17 @@ func NewRegister() *Register {
 //	if bufferSize is 0, the handler will not be stored.
 //	the bufferSize determines how many values ar send to the handler.
 //	the handler will not be called, if the bufferSize is larger than the count of available values
+//	with WithBufferWindow() all values within the window are send to the handler, and bufferSize is the minimal count of values
 //	the handler will only be called for devices/services with matching functions and aspects (aspect-hierarchy is observed)
 //	if no device/service with matching function and aspect is found, the handler will never be called
 //	the characteristic determines to what characteristic the incoming values are converted
-func (this *Register) Register(name string, function string, aspect string, characteristic string, bufferSize int, handler Handler) {
+//	options may be used to change the default behavior of the entry (e.g. WithAutoResolve())
+//	returns an error if parameters set with WithParameters() are invalid; the entry is not stored in this case
+func (this *Register) Register(name string, function string, aspect string, characteristic string, bufferSize int, handler Handler, options ...Option) error {
 	if bufferSize == 0 {
-		return
+		return nil
 	}
-	this.entries[name] = Entry{
+	entry := Entry{
 		Name:           name,
 		Function:       function,
 		Aspect:         aspect,
@@ -61,6 +193,67 @@ func (this *Register) Register(name string, function string, aspect string, char
 		BufferSize:     bufferSize,
 		Handler:        handler,
 	}
+	for _, option := range options {
+		option(&entry)
+	}
+	if entry.Severity == "" {
+		entry.Severity = SeverityWarning
+	}
+	err := entry.Severity.Validate()
+	if err != nil {
+		return fmt.Errorf("handler %v: %w", name, err)
+	}
+	if entry.Mode == "" {
+		entry.Mode = ModeActive
+	}
+	err = entry.Mode.Validate()
+	if err != nil {
+		return fmt.Errorf("handler %v: %w", name, err)
+	}
+	definitions := entry.ParameterDefinitions()
+	if definitions == nil && len(entry.Parameters) > 0 {
+		return fmt.Errorf("handler %v does not accept parameters", name)
+	}
+	parameters, err := definitions.Parse(entry.Parameters)
+	if err != nil {
+		return fmt.Errorf("handler %v: %w", name, err)
+	}
+	entry.Parameters = parameters
+	this.entries[name] = entry
+	return nil
+}
+
+// MustRegister is like Register but panics if the entry is invalid;
+// used for the built-in entries, which are registered in init functions
+func (this *Register) MustRegister(name string, function string, aspect string, characteristic string, bufferSize int, handler Handler, options ...Option) {
+	err := this.Register(name, function, aspect, characteristic, bufferSize, handler, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Select returns a new register with the named entries
+// returns an error if an entry is unknown
+func (this *Register) Select(names ...string) (*Register, error) {
+	result := NewRegister()
+	for _, name := range names {
+		entry, ok := this.entries[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown handler %v", name)
+		}
+		result.entries[name] = entry
+	}
+	return result, nil
+}
+
+// WithMode returns a new register with the entries of this register in the given mode
+func (this *Register) WithMode(mode Mode) *Register {
+	result := NewRegister()
+	for name, entry := range this.entries {
+		entry.Mode = mode
+		result.entries[name] = entry
+	}
+	return result
 }
 
 func (this *Register) List() (result []Entry) {
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegister_Select(t *testing.T) {
+	register := NewRegister()
+	for _, name := range []string{"a", "b", "c"} {
+		err := register.Register(name, "f", "a", "c", 2, JumpBackHandler{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	selected, err := register.Select("a", "c")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	names := map[string]bool{}
+	for _, entry := range selected.List() {
+		names[entry.Name] = true
+	}
+	if len(names) != 2 || !names["a"] || !names["c"] {
+		t.Errorf("unexpected selection %#v", names)
+	}
+	_, err = register.Select("a", "unknown")
+	if err == nil {
+		t.Error("expected error for unknown handler")
+	}
+}
+
+// a shadow entry of the same handler type must not change the state of the active (built-in) entry on the same device/service
+func TestRegister_ShadowEntryState(t *testing.T) {
+	register := NewRegister()
+	err := register.Register("active", "f", "a", "c", 2, BigJumpHandler{}, WithType(BigJumpType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = register.Register("shadow", "f", "a", "c", 2, BigJumpHandler{}, WithType(BigJumpType), WithOwnState(), WithMode(ModeShadow), WithParameters(map[string]interface{}{"sigma": 2.0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, _ := register.Select("active")
+	shadow, _ := register.Select("shadow")
+	activeEntry, shadowEntry := active.List()[0], shadow.List()[0]
+
+	alone := &TestStore{}
+	shared := &TestStore{}
+	values := []float64{1, 2, 3, 4, 5, 6, 8, 9, 10, 20, 21, 22}
+	for i := 1; i < len(values); i++ {
+		pair := []interface{}{values[i-1], values[i]}
+		context := Context{DeviceId: "test-device", ServiceId: "test-service"}
+
+		context.Store = alone
+		want, err := activeEntry.Evaluate(context, pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		context.Store = shared
+		_, err = shadowEntry.Evaluate(context, pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := activeEntry.Evaluate(context, pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("step %v: active result changed by shadow entry: got = %#v, want %#v", i, got, want)
+		}
+	}
+	activeState := map[string]interface{}{}
+	for key, value := range shared.values {
+		if strings.HasPrefix(key, "handlerstore_big_jump_test-device_") {
+			activeState[key] = value
+		}
+	}
+	if len(activeState) == 0 || !reflect.DeepEqual(activeState, alone.values) {
+		t.Errorf("active state changed by shadow entry: got = %#v, want %#v", activeState, alone.values)
+	}
+}
+
+// built-in entries must keep using the state, which has been stored before entries could have their own state
+func TestRegister_BuiltInEntryState(t *testing.T) {
+	selected, err := Registry.Select("big_jump_anom_electricity_consumption_total_kwh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := selected.List()[0]
+	store := &TestStore{}
+	store.Set("handlerstore_big_jump_test-device_test-service_mean", 1.0)
+	store.Set("handlerstore_big_jump_test-device_test-service_stddev", 1.0)
+	store.Set("handlerstore_big_jump_test-device_test-service_num_datepoints", 100.0)
+
+	//a jump of 2 is an anomaly without state, but not with the existing mean and stddev
+	result, err := entry.Evaluate(Context{DeviceId: "test-device", ServiceId: "test-service", Store: store}, []interface{}{1.0, 3.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Anomaly {
+		t.Errorf("existing state has not been used: %#v", result)
+	}
+	var count float64
+	err = store.Get("handlerstore_big_jump_test-device_test-service_num_datepoints", &count)
+	if err != nil || count != 101 {
+		t.Errorf("existing state has not been updated: %v %v", count, err)
+	}
+	if len(store.values) != 3 {
+		t.Errorf("unexpected state keys %#v", store.values)
+	}
+}
+
+func TestRegister_MustRegister(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid entry")
+		}
+	}()
+	NewRegister().MustRegister("invalid", "f", "a", "c", 2, BigJumpHandler{}, WithParameters(map[string]interface{}{"sigma": -1.0}))
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"math"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+var ErrInvalidSeverity = errors.New("invalid severity; expected info, warning or critical")
+
+func (this Severity) Validate() error {
+	switch this {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return nil
+	default:
+		return ErrInvalidSeverity
+	}
+}
+
+// Result is the outcome of a ScoringHandler call
+type Result struct {
+	Anomaly     bool
+	Description string   //human-readable description of the anomaly
+	Score       float64  //handler specific rating of the anomaly (e.g. distance to the mean in standard deviations); higher is more anomalous
+	Severity    Severity //if empty, the default severity of the entry is used
+}
+
+// ScoringHandler is implemented by handlers which rate the anomalies they find.
+// if a handler implements ScoringHandler, HandleWithScore is used instead of Handle.
+// handlers without ScoringHandler report anomalies with a score of 1 and the default severity of the entry (see WithSeverity())
+type ScoringHandler interface {
+	Handler
+	HandleWithScore(context Context, values []interface{}) (result Result, err error)
+}
+
+/* Upper limit of sigma scores, to keep them json encodable if the standard deviation is 0*/
+const MaxSigmaScore = 1000
+
+// SigmaScore returns the distance of a deviation to the mean in standard deviations, limited to MaxSigmaScore
+func SigmaScore(deviation float64, stddev float64) float64 {
+	deviation = math.Abs(deviation)
+	if deviation == 0 {
+		return 0
+	}
+	if stddev == 0 {
+		return MaxSigmaScore
+	}
+	return math.Min(deviation/stddev, MaxSigmaScore)
+}
+
+// SigmaSeverity returns SeverityCritical if the score exceeds criticalSigma and SeverityWarning otherwise
+func SigmaSeverity(score float64, criticalSigma float64) Severity {
+	if score > criticalSigma {
+		return SeverityCritical
+	}
+	return SeverityWarning
+}
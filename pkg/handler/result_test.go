@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntry_Evaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		options []Option
+		store   map[string]interface{}
+		values  []interface{}
+		want    Result
+	}{
+		{
+			name:    "compatibility default severity",
+			handler: JumpBackHandler{},
+			values:  []interface{}{2.0, 1.0},
+			want:    Result{Anomaly: true, Description: "Meter reading jumped back.", Score: 1, Severity: SeverityWarning},
+		},
+		{
+			name:    "compatibility custom severity",
+			handler: JumpBackHandler{},
+			options: []Option{WithSeverity(SeverityCritical)},
+			values:  []interface{}{2.0, 1.0},
+			want:    Result{Anomaly: true, Description: "Meter reading jumped back.", Score: 1, Severity: SeverityCritical},
+		},
+		{
+			name:    "compatibility no anomaly",
+			handler: JumpBackHandler{},
+			values:  []interface{}{1.0, 2.0},
+			want:    Result{Severity: SeverityWarning},
+		},
+		{
+			name:    "scoring warning",
+			handler: BigJumpHandler{},
+			store: map[string]interface{}{
+				"handlerstore_big_jump_test-device_test-service_mean":           1.0,
+				"handlerstore_big_jump_test-device_test-service_stddev":         0.5,
+				"handlerstore_big_jump_test-device_test-service_num_datepoints": 10.0,
+			},
+			values: []interface{}{1.0, 5.0},
+			want:   Result{Anomaly: true, Description: "Meter reading had big jump.", Score: 6, Severity: SeverityWarning},
+		},
+		{
+			name:    "scoring critical",
+			handler: BigJumpHandler{},
+			store: map[string]interface{}{
+				"handlerstore_big_jump_test-device_test-service_mean":           1.0,
+				"handlerstore_big_jump_test-device_test-service_stddev":         0.5,
+				"handlerstore_big_jump_test-device_test-service_num_datepoints": 10.0,
+			},
+			values: []interface{}{1.0, 8.0},
+			want:   Result{Anomaly: true, Description: "Meter reading had big jump.", Score: 12, Severity: SeverityCritical},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			register := NewRegister()
+			err := register.Register("test", "f", "a", "c", 2, tt.handler, tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := &TestStore{}
+			for key, value := range tt.store {
+				store.Set(key, value)
+			}
+			entry := register.List()[0]
+			got, err := entry.Evaluate(Context{DeviceId: "test-device", ServiceId: "test-service", Store: store}, tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+const SeasonalType = "seasonal"
+
+func init() {
+	RegisterType(SeasonalType, SeasonalHandler{})
+
+	/* Get Electricity Consumption, Electricity-->Total Subaspect, kWh */
+	Registry.MustRegister("seasonal_anom_electricity_consumption_total_kwh", "urn:infai:ses:measuring-function:57dfd369-92db-462c-aca4-a767b52c972e", "urn:infai:ses:aspect:fdc999eb-d366-44e8-9d24-bfd48d5fece1", "urn:infai:ses:characteristic:3febed55-ba9b-43dc-8709-9c73bae3716e", 2, SeasonalHandler{}, WithType(SeasonalType))
+
+	/* Get Volume, Water, Liter */
+	Registry.MustRegister("seasonal_anom_volume_water_liter", "urn:infai:ses:measuring-function:cfa56e75-8e8f-4f0d-a3fa-ed2758422b2a", "urn:infai:ses:aspect:b8b3b549-3b01-4604-a727-20aa528c21c9", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, SeasonalHandler{}, WithType(SeasonalType))
+
+	/* Get Gas Consumption, Gas, Liter*/
+	Registry.MustRegister("seasonal_anom_consumption_gas_liter", "urn:infai:ses:measuring-function:4daa591f-ad97-4e57-8014-aa3f5e552c3b", "urn:infai:ses:aspect:7ea324c1-48e4-419a-a499-325d79dac09f", "urn:infai:ses:characteristic:aeb260f8-5fe5-4989-9e66-3c0a4ff273c4", 2, SeasonalHandler{}, WithType(SeasonalType))
+}
+
+/* Default number of differences a time slot needs, before anomalies are reported for it*/
+const SeasonalMinDatapoints = 10
+
+/* Default distance to the slot mean in standard deviations, from which on a difference is an anomaly*/
+const SeasonalSigma = 5
+
+/* Default distance to the slot mean in standard deviations, from which on an anomaly is critical*/
+const SeasonalCriticalSigma = 10
+
+// SeasonalHandler keeps a separate baseline (mean/stddev of differences between consecutive meter values)
+// for each hour of the week and reports differences far outside the baseline of the time slot of the newest value.
+// the time slot is determined in the time zone of the "location" parameter (default: local time zone of the service),
+// which may be set per device with the attribute <attr>:seasonal:location (e.g. "Europe/Berlin").
+type SeasonalHandler struct{}
+
+func (this SeasonalHandler) ParameterDefinitions() ParameterDefinitions {
+	return ParameterDefinitions{
+		"sigma":          {Type: FloatParameter, Default: float64(SeasonalSigma), Validate: Positive},
+		"critical_sigma": {Type: FloatParameter, Default: float64(SeasonalCriticalSigma), Validate: Positive},
+		"min_datapoints": {Type: IntParameter, Default: int64(SeasonalMinDatapoints), Validate: NonNegative},
+		"location":       {Type: LocationParameter, Default: time.Local},
+	}
+}
+
+type SeasonalSlot struct {
+	Mean          float64 `json:"mean"`
+	Stddev        float64 `json:"stddev"`
+	NumDatepoints float64 `json:"num_datepoints"`
+}
+
+func (this SeasonalHandler) Handle(context Context, values []interface{}) (anomaly bool, description string, err error) {
+	result, err := this.HandleWithScore(context, values)
+	return result.Anomaly, result.Description, err
+}
+
+// HandleWithScore rates anomalies by their distance to the slot mean in standard deviations
+func (this SeasonalHandler) HandleWithScore(context Context, values []interface{}) (result Result, err error) {
+	parameters := context.Parameters.WithDefaults(this.ParameterDefinitions())
+	castValues, err := CastList[float64](values)
+	if err != nil {
+		return result, err
+	}
+
+	latestDifference := castValues[1] - castValues[0]
+
+	key := context.PrepareKey("seasonal", fmt.Sprintf("slot_%v", HourOfWeek(time.Unix(context.Timestamp, 0).In(parameters.Location("location")))))
+	var slot SeasonalSlot
+	err = context.Store.Get(key, &slot)
+	if err != nil {
+		slot = SeasonalSlot{}
+	}
+
+	deviation := latestDifference - slot.Mean
+	score := SigmaScore(deviation, slot.Stddev)
+	anomaly := slot.NumDatepoints >= float64(parameters.Int("min_datapoints")) && math.Abs(deviation) > parameters.Float("sigma")*slot.Stddev
+
+	slot.Stddev = UpdateStddev(latestDifference, slot.Stddev, slot.Mean, slot.NumDatepoints)
+	slot.Mean = UpdateMean(latestDifference, slot.Mean, slot.NumDatepoints)
+	slot.NumDatepoints = slot.NumDatepoints + 1
+	err = context.Store.Set(key, slot)
+	if err != nil {
+		return result, err
+	}
+
+	if !anomaly {
+		return result, nil
+	}
+	var description string
+	if deviation > 0 {
+		description = "Consumption is unusually high for this time of the week."
+	} else {
+		description = "Consumption is unusually low for this time of the week."
+	}
+	log.Println(description)
+	return Result{
+		Anomaly:     true,
+		Description: description,
+		Score:       score,
+		Severity:    SigmaSeverity(score, parameters.Float("critical_sigma")),
+	}, nil
+}
+
+// HourOfWeek returns the hour since the start of the week (sunday 00:00) in the range of 0 to 167
+func HourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
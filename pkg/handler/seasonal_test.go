@@ -0,0 +1,163 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSeasonalHandler_Handle(t *testing.T) {
+	type args struct {
+		values []interface{}
+	}
+	tests := []struct {
+		name            string
+		args            args
+		slot            SeasonalSlot
+		wantAnomaly     bool
+		wantDescription string
+		wantErr         bool
+	}{
+		{
+			name: "usual_consumption",
+			args: args{
+				values: []interface{}{1.0, 3.4},
+			},
+			slot:            SeasonalSlot{Mean: 2.0, Stddev: 0.1, NumDatepoints: 20},
+			wantAnomaly:     false,
+			wantDescription: "",
+			wantErr:         false,
+		},
+		{
+			name: "high_consumption",
+			args: args{
+				values: []interface{}{1.0, 3.6},
+			},
+			slot:            SeasonalSlot{Mean: 2.0, Stddev: 0.1, NumDatepoints: 20},
+			wantAnomaly:     true,
+			wantDescription: "Consumption is unusually high for this time of the week.",
+			wantErr:         false,
+		},
+		{
+			name: "low_consumption",
+			args: args{
+				values: []interface{}{1.0, 1.4},
+			},
+			slot:            SeasonalSlot{Mean: 2.0, Stddev: 0.1, NumDatepoints: 20},
+			wantAnomaly:     true,
+			wantDescription: "Consumption is unusually low for this time of the week.",
+			wantErr:         false,
+		},
+		{
+			name: "not_enough_datapoints",
+			args: args{
+				values: []interface{}{1.0, 5.0},
+			},
+			slot:            SeasonalSlot{Mean: 2.0, Stddev: 0.1, NumDatepoints: 4},
+			wantAnomaly:     false,
+			wantDescription: "",
+			wantErr:         false,
+		},
+		{
+			name: "unknown_slot",
+			args: args{
+				values: []interface{}{1.0, 5.0},
+			},
+			wantAnomaly:     false,
+			wantDescription: "",
+			wantErr:         false,
+		},
+	}
+	timestamp := time.Date(2025, 3, 12, 18, 30, 0, 0, time.Local)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &TestStore{}
+			if tt.slot.NumDatepoints > 0 {
+				store.Set(fmt.Sprintf("handlerstore_seasonal_test-device_test-service_slot_%v", HourOfWeek(timestamp)), tt.slot)
+			}
+			this := SeasonalHandler{}
+			gotAnomaly, gotDescription, err := this.Handle(Context{DeviceId: "test-device", ServiceId: "test-service", Timestamp: timestamp.Unix(), Store: store, Parameters: this.ParameterDefinitions().Defaults()}, tt.args.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Handle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotAnomaly != tt.wantAnomaly {
+				t.Errorf("Handle() gotAnomaly = %v, want %v", gotAnomaly, tt.wantAnomaly)
+			}
+			if gotDescription != tt.wantDescription {
+				t.Errorf("Handle() gotDescription = %v, want %v", gotDescription, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestHourOfWeek(t *testing.T) {
+	if h := HourOfWeek(time.Date(2025, 3, 9, 0, 15, 0, 0, time.UTC)); h != 0 { //sunday
+		t.Errorf("unexpected hour of week %v", h)
+	}
+	if h := HourOfWeek(time.Date(2025, 3, 12, 18, 30, 0, 0, time.UTC)); h != 3*24+18 { //wednesday
+		t.Errorf("unexpected hour of week %v", h)
+	}
+	if h := HourOfWeek(time.Date(2025, 3, 15, 23, 59, 0, 0, time.UTC)); h != 167 { //saturday
+		t.Errorf("unexpected hour of week %v", h)
+	}
+}
+
+func TestSeasonalHandler_Location(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	this := SeasonalHandler{}
+	timestamp := time.Date(2025, 3, 9, 23, 30, 0, 0, time.UTC) //sunday 23:30 UTC is monday 00:30 in berlin
+	tests := []struct {
+		name     string
+		location interface{}
+		wantSlot int
+	}{
+		{name: "utc", location: "UTC", wantSlot: 23},
+		{name: "berlin", location: "Europe/Berlin", wantSlot: 24},
+		{name: "location", location: berlin, wantSlot: 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters, err := this.ParameterDefinitions().Parse(map[string]interface{}{"location": tt.location})
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := &TestStore{}
+			_, err = this.HandleWithScore(Context{DeviceId: "test-device", ServiceId: "test-service", Timestamp: timestamp.Unix(), Store: store, Parameters: parameters}, []interface{}{1.0, 2.0})
+			if err != nil {
+				t.Fatal(err)
+			}
+			slot := SeasonalSlot{}
+			err = store.Get(fmt.Sprintf("handlerstore_seasonal_test-device_test-service_slot_%v", tt.wantSlot), &slot)
+			if err != nil || slot.NumDatepoints != 1 {
+				t.Errorf("expected slot %v to be updated, got %#v %v", tt.wantSlot, slot, err)
+			}
+		})
+	}
+
+	t.Run("invalid location", func(t *testing.T) {
+		_, err := this.ParameterDefinitions().Parse(map[string]interface{}{"location": "Mars/Olympus"})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/event"
+	"net/http"
+)
+
+type Metrics struct {
+	EventsConsumed       *prometheus.CounterVec
+	EventsMatched        *prometheus.CounterVec
+	MarshallingFailures  *prometheus.CounterVec
+	HandlerErrors        *prometheus.CounterVec
+	HandlerPanics        *prometheus.CounterVec
+	AnomaliesDetected    *prometheus.CounterVec
+	NotificationFailures prometheus.Counter
+	ValkeyLatency        *prometheus.HistogramVec
+	MongoLatency         *prometheus.HistogramVec
+	RegisterDevices      prometheus.Gauge
+	RegisterServices     prometheus.Gauge
+	RegisterTopics       prometheus.Gauge
+
+	registry *prometheus.Registry
+}
+
+func New() *Metrics {
+	reg := prometheus.NewRegistry()
+	result := &Metrics{
+		registry: reg,
+		EventsConsumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "anomaly_detection_events_consumed_total",
+			Help: "number of events consumed from kafka",
+		}, []string{"topic"}),
+		EventsMatched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "anomaly_detection_events_matched_total",
+			Help: "number of events routed to a handler",
+		}, []string{"handler"}),
+		MarshallingFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "anomaly_detection_marshalling_failures_total",
+			Help: "number of events which could not be marshalled for a handler",
+		}, []string{"handler"}),
+		HandlerErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "anomaly_detection_handler_errors_total",
+			Help: "number of errors returned by handlers",
+		}, []string{"handler"}),
+		HandlerPanics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "anomaly_detection_handler_panics_total",
+			Help: "number of recovered handler panics",
+		}, []string{"handler"}),
+		AnomaliesDetected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "anomaly_detection_anomalies_detected_total",
+			Help: "number of detected anomalies",
+		}, []string{"handler", "severity"}),
+		NotificationFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "anomaly_detection_notification_failures_total",
+			Help: "number of notifications which could not be sent",
+		}),
+		ValkeyLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "anomaly_detection_valkey_latency_seconds",
+			Help:    "latency of valkey commands",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"command"}),
+		MongoLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "anomaly_detection_mongo_latency_seconds",
+			Help:    "latency of mongodb commands",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"command"}),
+		RegisterDevices: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "anomaly_detection_register_devices",
+			Help: "number of devices matched by at least one handler",
+		}),
+		RegisterServices: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "anomaly_detection_register_services",
+			Help: "number of matched device/service combinations",
+		}),
+		RegisterTopics: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "anomaly_detection_register_topics",
+			Help: "number of consumed kafka topics",
+		}),
+	}
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		result.EventsConsumed,
+		result.EventsMatched,
+		result.MarshallingFailures,
+		result.HandlerErrors,
+		result.HandlerPanics,
+		result.AnomaliesDetected,
+		result.NotificationFailures,
+		result.ValkeyLatency,
+		result.MongoLatency,
+		result.RegisterDevices,
+		result.RegisterServices,
+		result.RegisterTopics,
+	)
+	return result
+}
+
+func (this *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(this.registry, promhttp.HandlerOpts{Registry: this.registry})
+}
+
+// MongoMonitor records the latency of mongodb commands
+func (this *Metrics) MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			this.MongoLatency.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			this.MongoLatency.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	m := New()
+	m.EventsConsumed.WithLabelValues("topic_1").Inc()
+	m.AnomaliesDetected.WithLabelValues("big_jump", "warning").Inc()
+	m.RegisterDevices.Set(3)
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, expected := range []string{
+		`anomaly_detection_events_consumed_total{topic="topic_1"} 1`,
+		`anomaly_detection_anomalies_detected_total{handler="big_jump",severity="warning"} 1`,
+		`anomaly_detection_register_devices 3`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("missing %v in\n%v", expected, string(body))
+		}
+	}
+}
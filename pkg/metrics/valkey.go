@@ -0,0 +1,57 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"github.com/valkey-io/valkey-go"
+	"strings"
+	"time"
+)
+
+// WrapValkey returns a client, which records the latency of Do and DoMulti calls
+// DoMulti calls are recorded as PIPELINE; lua scripts are recorded as EVALSHA/EVAL
+func (this *Metrics) WrapValkey(client valkey.Client) valkey.Client {
+	return &valkeyClient{Client: client, metrics: this}
+}
+
+type valkeyClient struct {
+	valkey.Client
+	metrics *Metrics
+}
+
+func (this *valkeyClient) Do(ctx context.Context, cmd valkey.Completed) (resp valkey.ValkeyResult) {
+	command := commandName(cmd.Commands())
+	start := time.Now()
+	resp = this.Client.Do(ctx, cmd)
+	this.metrics.ValkeyLatency.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	return resp
+}
+
+func (this *valkeyClient) DoMulti(ctx context.Context, multi ...valkey.Completed) (resp []valkey.ValkeyResult) {
+	start := time.Now()
+	resp = this.Client.DoMulti(ctx, multi...)
+	this.metrics.ValkeyLatency.WithLabelValues("PIPELINE").Observe(time.Since(start).Seconds())
+	return resp
+}
+
+func commandName(commands []string) string {
+	if len(commands) == 0 {
+		return ""
+	}
+	return strings.ToUpper(commands[0])
+}
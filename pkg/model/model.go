@@ -18,6 +18,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -34,10 +35,48 @@ type EventMessage struct {
 
 type ConsumerMessage struct {
 	Topic     string `json:"topic"`
+	Key       string `json:"key"`
 	Message   []byte `json:"message"`
 	Timestamp int64  `json:"timestamp"`
 }
 
+// DeadLetter is published to the dead-letter topic for events, which could not be processed (ErrWillBeIgnored)
+type DeadLetter struct {
+	Topic          string   `json:"topic"`              //source topic of the event
+	Key            string   `json:"key"`                //kafka key of the original event
+	Message        string   `json:"message"`            //original event
+	Timestamp      int64    `json:"timestamp"`          //unix timestamp in seconds of the original event
+	Error          string   `json:"error"`              //reason why the event was not processed
+	ErrorTimestamp int64    `json:"error_timestamp"`    //unix timestamp in seconds of the failed processing
+	Handlers       []string `json:"handlers,omitempty"` //handlers, which failed to process the event; empty if no handler received the event (e.g. invalid json)
+}
+
+// HandlerError is returned for events, which some handlers failed to process, while the other handlers processed them
+type HandlerError struct {
+	Handlers []string //names of the failed handlers
+	Err      error
+}
+
+func (this *HandlerError) Error() string {
+	return fmt.Sprintf("handlers %v failed: %v", this.Handlers, this.Err)
+}
+
+func (this *HandlerError) Unwrap() error {
+	return this.Err
+}
+
+// ServiceStatus reports components, which failed and did not recover yet
+type ServiceStatus struct {
+	Degraded bool              `json:"degraded"`
+	Failures map[string]string `json:"failures"` //error by component
+}
+
+// Readiness reports if the service and its dependencies are able to process events
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"` //"ok" or error by check
+}
+
 var ErrWithRetry = errors.New("will be retried")
 var ErrWillBeIgnored = errors.New("entry will be ignored")
 
@@ -18,6 +18,7 @@ package pkg
 
 import (
 	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/api"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller"
 	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
@@ -25,6 +26,22 @@ import (
 )
 
 func Start(ctx context.Context, wg *sync.WaitGroup, config configuration.Config) error {
-	_, err := controller.StartController(ctx, wg, config, handler.Registry)
-	return err
+	if config.HandlerConfigLocation != "" && config.HandlerConfigLocation != "-" {
+		err := handler.Registry.LoadConfig(config.HandlerConfigLocation)
+		if err != nil {
+			return err
+		}
+	}
+	//the api is started first, to serve /health and /ready while the controller connects and loads the register
+	starting := api.NewStartingController()
+	err := api.Start(ctx, wg, config, starting)
+	if err != nil {
+		return err
+	}
+	ctrl, err := controller.StartController(ctx, wg, config, handler.Registry)
+	if err != nil {
+		return err
+	}
+	starting.Set(ctrl)
+	return nil
 }
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/configuration"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/controller/anomalystore"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/handler"
+	"github.com/SENERGY-Platform/anomaly-detection-service/pkg/tests/docker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
+	"testing"
+)
+
+func TestLegacyAnomalyMigration(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, mongoIp, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://" + mongoIp + ":27017"
+
+	//documents of the service before the anomaly lifecycle existed
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(config.MongoUrl))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer client.Disconnect(context.Background())
+	_, err = client.Database(config.MongoTable).Collection(config.MongoAnomalyCollection).InsertMany(ctx, []interface{}{
+		bson.M{"handler": "h", "device": "d", "service": "s", "description": "legacy 1", "unix_timestamp": int64(10)},
+		bson.M{"handler": "h", "device": "d", "service": "s", "description": "legacy 2", "unix_timestamp": int64(20)},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	store, err := anomalystore.New(config, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer store.Disconnect()
+
+	t.Run("legacy anomalies are resolved", func(t *testing.T) {
+		list, total, err := store.ListAnomalies(anomalystore.AnomalyQuery{Status: anomalystore.StatusResolved})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if total != 2 {
+			t.Errorf("unexpected anomalies %#v", list)
+			return
+		}
+		for _, anomaly := range list {
+			if anomaly.Id == "" || anomaly.Detections != 1 || len(anomaly.StatusHistory) != 2 {
+				t.Errorf("unexpected anomaly %#v", anomaly)
+			}
+		}
+	})
+
+	t.Run("new detection opens a new anomaly", func(t *testing.T) {
+		err = store.StoreAnomaly("h", "d", "s", "new", 1, handler.SeverityWarning, 30, false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, total, err := store.ListAnomalies(anomalystore.AnomalyQuery{Status: anomalystore.StatusOpen})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if total != 1 || list[0].Id == "" || list[0].Description != "new" || list[0].Detections != 1 {
+			t.Errorf("unexpected anomalies %#v", list)
+		}
+	})
+
+	t.Run("repeated start", func(t *testing.T) {
+		again, err := anomalystore.New(config, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		again.Disconnect()
+	})
+}
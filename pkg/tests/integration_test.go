@@ -30,6 +30,7 @@ import (
 	"github.com/SENERGY-Platform/models/go/models"
 	permissions "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 	model2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	jwtlib "github.com/golang-jwt/jwt"
 	"github.com/segmentio/kafka-go"
 	"github.com/valkey-io/valkey-go"
 	"go.mongodb.org/mongo-driver/bson"
@@ -331,10 +332,10 @@ func TestIntegration(t *testing.T) {
 	}
 
 	reg := handler.NewRegister()
-	reg.Register("test", functionId, aspectId, characteristicId1, 5, testHandler)
+	reg.Register("test", functionId, aspectId, characteristicId1, 5, testHandler, handler.WithAutoResolve())
 	reg.Register("ignoredTestHandler", functionIgnoredId, aspectId, characteristicId1, 5, ignoredTestHandler)
 
-	_, err = controller.StartController(ctx, wg, config, reg)
+	ctrl, err := controller.StartController(ctx, wg, config, reg)
 	if err != nil {
 		t.Error(err)
 		return
@@ -490,11 +491,12 @@ func TestIntegration(t *testing.T) {
 		defer notificationsMux.Unlock()
 		expected := map[string][]string{
 			"/notifications?ignore_duplicates_within_seconds=86400": {
-				`{"userId":"owner","title":"Anomaly Detected","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\n","topic":"analytics"}` + "\n",
-				`{"userId":"owner","title":"Anomaly Detected","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\n","topic":"analytics"}` + "\n",
-				`{"userId":"owner","title":"Anomaly Detected","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\n","topic":"analytics"}` + "\n",
-				`{"userId":"owner","title":"Anomaly Detected","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\n","topic":"analytics"}` + "\n",
-				`{"userId":"owner","title":"Anomaly Detected","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\n","topic":"analytics"}` + "\n",
+				`{"userId":"owner","title":"Anomaly Detected (warning)","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\nscore: 1\n","topic":"analytics"}` + "\n",
+				`{"userId":"owner","title":"Anomaly Detected (warning)","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\nscore: 1\n","topic":"analytics"}` + "\n",
+				`{"userId":"owner","title":"Anomaly Detected (warning)","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\nscore: 1\n","topic":"analytics"}` + "\n",
+				`{"userId":"owner","title":"Anomaly Detected (warning)","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\nscore: 1\n","topic":"analytics"}` + "\n",
+				`{"userId":"owner","title":"Anomaly Detected (warning)","message":"test anomaly detected for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\ndesc: contains 100\nscore: 1\n","topic":"analytics"}` + "\n",
+				`{"userId":"owner","title":"Anomaly Resolved","message":"test anomaly resolved for device device1 (urn:infai:ses:device:d1) in service urn:infai:ses:service:s1\n","topic":"analytics"}` + "\n",
 			},
 		}
 		if !reflect.DeepEqual(notifications, expected) {
@@ -518,17 +520,139 @@ func TestIntegration(t *testing.T) {
 			return
 		}
 		expected := []anomalystore.Anomaly{
-			{Handler: "test", Device: "urn:infai:ses:device:d1", Service: "urn:infai:ses:service:s1", Description: "contains 100", UnixTimestamp: now.Add(time.Duration(10) * time.Minute).Unix()},
-			{Handler: "test", Device: "urn:infai:ses:device:d1", Service: "urn:infai:ses:service:s1", Description: "contains 100", UnixTimestamp: now.Add(time.Duration(11) * time.Minute).Unix()},
-			{Handler: "test", Device: "urn:infai:ses:device:d1", Service: "urn:infai:ses:service:s1", Description: "contains 100", UnixTimestamp: now.Add(time.Duration(12) * time.Minute).Unix()},
-			{Handler: "test", Device: "urn:infai:ses:device:d1", Service: "urn:infai:ses:service:s1", Description: "contains 100", UnixTimestamp: now.Add(time.Duration(13) * time.Minute).Unix()},
-			{Handler: "test", Device: "urn:infai:ses:device:d1", Service: "urn:infai:ses:service:s1", Description: "contains 100", UnixTimestamp: now.Add(time.Duration(14) * time.Minute).Unix()},
+			{
+				Handler:           "test",
+				Device:            "urn:infai:ses:device:d1",
+				Service:           "urn:infai:ses:service:s1",
+				Description:       "contains 100",
+				Score:             1,
+				Severity:          handler.SeverityWarning,
+				UnixTimestamp:     now.Add(time.Duration(10) * time.Minute).Unix(),
+				LastUnixTimestamp: now.Add(time.Duration(14) * time.Minute).Unix(),
+				Detections:        5,
+				Status:            anomalystore.StatusResolved,
+				StatusHistory: []anomalystore.StatusChange{
+					{Status: anomalystore.StatusOpen, UnixTimestamp: now.Add(time.Duration(10) * time.Minute).Unix()},
+					{Status: anomalystore.StatusResolved, UnixTimestamp: now.Add(time.Duration(15) * time.Minute).Unix()},
+				},
+			},
+		}
+		for i := range list {
+			if list[i].Id == "" {
+				t.Error("missing anomaly id")
+			}
+			list[i].Id = ""
 		}
 		if !reflect.DeepEqual(list, expected) {
 			t.Errorf("unexpected anomaly\ne=%#v\na=%#v\n", expected, list)
 		}
 	})
 
+	t.Run("check api", func(t *testing.T) {
+		ownerToken, err := createToken("owner")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, total, err, _ := ctrl.ListAnomalies(ownerToken, anomalystore.AnomalyQuery{
+			Device: deviceId,
+			Status: anomalystore.StatusResolved,
+			Sort:   "unix_timestamp.asc",
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if total != 1 || len(list) != 1 {
+			t.Errorf("unexpected anomalies %v %#v", total, list)
+			return
+		}
+		if list[0].Detections != 5 || list[0].Status != anomalystore.StatusResolved {
+			t.Errorf("unexpected anomaly %#v", list[0])
+		}
+		_, err, code := ctrl.SetAnomalyStatus(ownerToken, list[0].Id, anomalystore.StatusAcknowledged)
+		if code != http.StatusConflict {
+			t.Errorf("resolved anomaly should not be acknowledgeable %v %v", code, err)
+		}
+
+		otherToken, err := createToken("other")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, total, err, _ = ctrl.ListAnomalies(otherToken, anomalystore.AnomalyQuery{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if total != 0 || len(list) != 0 {
+			t.Errorf("unexpected anomalies for other user %v %#v", total, list)
+		}
+
+		//new anomaly after resolve
+		err = send(map[string]interface{}{
+			"root": map[string]interface{}{
+				"time":  now.Add(20 * time.Minute).Unix(),
+				"value": 10,
+			},
+		}, 20*time.Minute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		time.Sleep(5 * time.Second)
+
+		list, total, err, _ = ctrl.ListAnomalies(ownerToken, anomalystore.AnomalyQuery{
+			Device: deviceId,
+			Status: anomalystore.StatusOpen,
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if total != 1 || len(list) != 1 {
+			t.Errorf("unexpected open anomalies %v %#v", total, list)
+			return
+		}
+		id := list[0].Id
+
+		_, err, _ = ctrl.SetAnomalyStatus(otherToken, id, anomalystore.StatusAcknowledged)
+		if err == nil {
+			t.Error("other user should not be able to acknowledge the anomaly")
+		}
+
+		anomaly, err, _ := ctrl.SetAnomalyStatus(ownerToken, id, anomalystore.StatusAcknowledged)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if anomaly.Status != anomalystore.StatusAcknowledged || len(anomaly.StatusHistory) != 2 || anomaly.StatusHistory[1].User != "owner" {
+			t.Errorf("unexpected anomaly %#v", anomaly)
+		}
+		_, err, code = ctrl.SetAnomalyStatus(ownerToken, id, anomalystore.StatusAcknowledged)
+		if code != http.StatusConflict {
+			t.Errorf("unexpected status transition result %v %v", code, err)
+		}
+		anomaly, err, _ = ctrl.SetAnomalyStatus(ownerToken, id, anomalystore.StatusResolved)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if anomaly.Status != anomalystore.StatusResolved || len(anomaly.StatusHistory) != 3 {
+			t.Errorf("unexpected anomaly %#v", anomaly)
+		}
+	})
+}
+
+func createToken(userId string, roles ...string) (string, error) {
+	token, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwtlib.MapClaims{
+		"sub":          userId,
+		"realm_access": map[string][]string{"roles": append(roles, "user")},
+	}).SignedString([]byte("test"))
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + token, nil
 }
 
 type TestHandler struct {